@@ -13,22 +13,41 @@
 // @host localhost:8080
 // @BasePath /
 // @schemes http
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/auth"
+	"github.com/rosadsky/ros-project-backend/internal/config"
 	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/fieldcrypt"
+	"github.com/rosadsky/ros-project-backend/internal/health"
 	"github.com/rosadsky/ros-project-backend/internal/hospital_spaces"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+	"github.com/rosadsky/ros-project-backend/internal/metrics"
+	"github.com/rosadsky/ros-project-backend/internal/middleware"
+	"github.com/rosadsky/ros-project-backend/internal/migrations"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"github.com/rosadsky/ros-project-backend/internal/ratelimit"
+	"github.com/rosadsky/ros-project-backend/internal/tracing"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 
 	// Swagger imports
 	_ "github.com/rosadsky/ros-project-backend/docs" // Import generated docs
@@ -37,77 +56,190 @@ import (
 )
 
 func main() {
-	// Initialize logger
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	// Load configuration from config/config.yaml and AMBULANCE_API_* overrides
+	cfg, err := config.Load()
+	if err != nil {
+		bootstrapLogger := logging.New(config.Default().Logging)
+		bootstrapLogger.Fatal().Err(err).Msg("Failed to load configuration")
+	}
+
+	// Initialize the logger shared by all components, request handlers get it with
+	// the request ID attached through the request context
+	logger := logging.New(cfg.Logging).Hook(tracing.LogHook{})
+	zerolog.DefaultContextLogger = &logger
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		logger.Debug().Msgf(strings.TrimSpace(format), values...)
+	}
+	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, _ int) {
+		logger.Debug().Str("method", httpMethod).Str("route", absolutePath).Str("handler", handlerName).Msg("Route registered")
+	}
+
+	// Pick up runtime configuration changes from the file or on SIGHUP
+	reloader := config.NewReloader(cfg, logger)
+	reloader.OnReload(func(cfg *config.Config) {
+		logging.SetLevel(cfg.Logging.Level)
+	})
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go reloader.Watch(backgroundCtx)
+
+	// Collect Prometheus metrics, including the latency of database commands
+	dbOptions := db_service.OptionsFromConfig(cfg.MongoDB)
+	var serviceMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		serviceMetrics = metrics.New()
+		dbOptions.Monitors = append(dbOptions.Monitors, serviceMetrics.CommandMonitor())
+	}
 
-	// Initialize database service
-	dbService := db_service.NewDbService()
+	// Trace HTTP requests and database commands, spans are flushed on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed to flush traces")
+		}
+	}()
+	if cfg.Tracing.Enabled {
+		dbOptions.Monitors = append(dbOptions.Monitors, otelmongo.NewMonitor())
+	}
+
+	// Connect to the database, the connection is owned by main until shutdown
+	dbService, err := db_service.New(context.Background(), dbOptions)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	logger.Info().Str("uri", logging.RedactURI(dbOptions.URI)).Str("database", cfg.MongoDB.Database).Bool("lazy", dbOptions.Lazy).Msg("Connected to MongoDB")
 	defer func() {
 		if err := dbService.Disconnect(); err != nil {
 			logger.Error().Err(err).Msg("Failed to disconnect from database")
 		}
 	}()
 
-	// Make index creation optional for development
-	if err := dbService.EnsureIndexes(); err != nil {
-		log.Printf("Warning: Failed to create database indexes: %v", err)
-		log.Println("Continuing without indexes for development...")
-		// Don't exit, just continue
-	} else {
-		log.Println("Database indexes created successfully")
+	// Readiness reports the database state and fails while migrations are pending or
+	// required indexes are missing
+	healthChecker := health.NewChecker(dbService, cfg.Health.PingTimeout, cfg.Health.CacheTTL)
+	migrationRunner := migrations.NewRunner(dbService, logger)
+	healthChecker.SetMigrations(migrationRunner.Pending)
+	healthChecker.SetIndexes(func(ctx context.Context) ([]string, error) {
+		return migrations.MissingIndexes(ctx, dbService)
+	})
+
+	// Apply pending migrations unless they run as a separate job. The server starts
+	// either way, readiness keeps it out of rotation until the migrations are applied.
+	if cfg.Migrations.AutoApply {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Migrations.Timeout)
+		applied, err := migrationRunner.Up(ctx)
+		cancel()
+		if err != nil {
+			logger.Error().Err(err).Int("applied", len(applied)).Msg("Failed to apply migrations")
+		} else {
+			logger.Info().Int("applied", len(applied)).Msg("Database migrations are up to date")
+		}
 	}
 
+	// Report binding errors with the JSON names of the fields
+	problem.UseJSONFieldNames()
+
 	// Create Gin router
-	router := gin.Default()
-
-	// Add CORS middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000",
-			"http://localhost:3333",
-		},
-		AllowMethods: []string{
-			"GET",
-			"POST",
-			"PUT",
-			"DELETE",
-			"OPTIONS",
-		},
-		AllowHeaders: []string{
-			"Origin",
-			"Content-Type",
-			"Authorization",
-		},
-		AllowCredentials: true,
-	}))
-
-	// Add other middleware
-	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+
+	// Start a span per request, continuing the trace of an incoming traceparent header
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(isTracedRequest)))
+
+	// Assign request IDs, log each request and recover from panics
+	router.Use(logging.Middleware(logger, "/healthz/live", "/healthz/ready", "/metrics"))
+	router.Use(problem.Recovery())
+
+	// Add CORS middleware, rebuilt whenever the configuration is reloaded
+	corsHandler, err := reloadableCORS(reloader)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up CORS")
+	}
+	router.Use(corsHandler)
+
+	// Expose /metrics and refresh the space and ambulance gauges in the background
+	if serviceMetrics != nil {
+		router.Use(serviceMetrics.Middleware())
+		serviceMetrics.RegisterRoutes(router)
+		go serviceMetrics.RunDomainRefresh(backgroundCtx, dbService, cfg.Metrics.RefreshInterval, logger)
+	}
+
+	// Purge deleted spaces and ambulances once their retention period has passed
+	if cfg.Retention.PurgeInterval > 0 {
+		go hospital_spaces.RunPurge(backgroundCtx, dbService, cfg.Retention.DeletedAfter, cfg.Retention.PurgeInterval, logger)
+	}
+
+	// Snapshot space occupancy for point-in-time queries
+	if cfg.Snapshots.Interval > 0 {
+		go hospital_spaces.RunSnapshots(backgroundCtx, dbService, cfg.Snapshots.Interval, cfg.Snapshots.Retention, logger)
+	}
+
+	// Liveness and readiness probes
+	healthChecker.RegisterRoutes(router)
+
+	// Bound each request by the deadline the client asked for
+	router.Use(middleware.RequestTimeout(cfg.Server.MaxRequestTimeout))
+
+	// Resolve the caller of each request from its API key
+	authenticator := auth.NewAuthenticator(dbService)
+	if cfg.Security.AdminKey != "" {
+		if err := authenticator.EnsureBootstrapKey(cfg.Security.AdminKey); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to register bootstrap admin API key")
+		}
+	}
+	router.Use(authenticator.Middleware())
+
+	// Limit the request rate and body size per client, keyed by the resolved API key
+	if cfg.Limits.Enabled {
+		var limitBackend ratelimit.Backend = ratelimit.NewMemoryBackend()
+		if cfg.Limits.Backend == "mongodb" {
+			limitBackend = ratelimit.NewMongoBackend(dbService)
+		}
+		router.Use(ratelimit.Middleware(reloader, limitBackend))
+	}
+	authenticator.RegisterRoutes(router)
+	reloader.RegisterRoutes(router.Group("/api/admin/config", auth.RequireRole(auth.RoleAdmin)))
+
+	// Load the field encryption keys, sensitive fields stay in plaintext without them
+	var keyring *fieldcrypt.Keyring
+	if cfg.Security.EncryptionKeyFile != "" {
+		keyring, err = fieldcrypt.LoadKeyring(cfg.Security.EncryptionKeyFile)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load encryption key file")
+		}
+		logger.Info().Str("active_key_id", keyring.ActiveKeyID()).Msg("Field encryption enabled")
+	} else {
+		logger.Warn().Msg("security.encryption_key_file not set, sensitive fields are stored in plaintext")
+	}
 
 	// Initialize and register routes
-	spaceRouter := hospital_spaces.NewSpaceAPIRouter(dbService)
+	spaceRouter := hospital_spaces.NewSpaceAPIRouter(dbService, keyring)
 	spaceRouter.RegisterRoutes(router)
 
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Get port from environment
-	port := os.Getenv("AMBULANCE_API_PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	// Create HTTP server
+	// Create HTTP server, request contexts derive from baseCtx so that in-flight
+	// database calls can be cancelled when a graceful shutdown runs out of time
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: router,
+		Addr:        cfg.Server.Address(),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	// Start server in a goroutine
 	go func() {
-		logger.Info().Str("port", port).Msg("Starting Hospital Spaces API server")
-		logger.Info().Msg("Swagger UI available at: http://localhost:8080/swagger/index.html")
+		logger.Info().Str("address", srv.Addr).Msg("Starting Hospital Spaces API server")
+		logger.Info().Msgf("Swagger UI available at: http://localhost:%d/swagger/index.html", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal().Err(err).Msg("Failed to start server")
 		}
@@ -118,6 +250,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info().Msg("Shutting down server...")
+	stopBackground()
 
 	// Give outstanding requests a deadline for completion
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -125,8 +258,52 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal().Err(err).Msg("Server forced to shutdown")
+		cancelRequests()
+		logger.Error().Err(err).Msg("Server forced to shutdown, in-flight requests were cancelled")
 	}
 
 	logger.Info().Msg("Server exited")
 }
+
+// isTracedRequest excludes probes and metrics scrapes from tracing
+func isTracedRequest(r *http.Request) bool {
+	return r.URL.Path != "/metrics" && !strings.HasPrefix(r.URL.Path, "/healthz/")
+}
+
+// reloadableCORS returns a CORS middleware that follows the CORS settings of the
+// current configuration. cors.New panics on settings it does not accept, so they
+// are validated first and a reload with such settings is rejected.
+func reloadableCORS(reloader *config.Reloader) (gin.HandlerFunc, error) {
+	corsConfig := func(cfg config.CORSConfig) cors.Config {
+		return cors.Config{
+			AllowOrigins:     cfg.AllowedOrigins,
+			AllowMethods:     cfg.AllowedMethods,
+			AllowHeaders:     cfg.AllowedHeaders,
+			AllowCredentials: cfg.AllowCredentials,
+			ExposeHeaders:    []string{logging.HeaderRequestID, ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderRetryAfter},
+		}
+	}
+
+	initial := corsConfig(reloader.Current().CORS)
+	if err := initial.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cors settings: %w", err)
+	}
+	var handler atomic.Pointer[gin.HandlerFunc]
+	current := cors.New(initial)
+	handler.Store(&current)
+
+	reloader.AddCheck(func(cfg *config.Config) error {
+		if err := corsConfig(cfg.CORS).Validate(); err != nil {
+			return fmt.Errorf("invalid cors settings: %w", err)
+		}
+		return nil
+	})
+	reloader.OnReload(func(cfg *config.Config) {
+		next := cors.New(corsConfig(cfg.CORS))
+		handler.Store(&next)
+	})
+
+	return func(c *gin.Context) {
+		(*handler.Load())(c)
+	}, nil
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/auth"
+	"github.com/rosadsky/ros-project-backend/internal/fieldcrypt"
+	"github.com/rosadsky/ros-project-backend/internal/hospital_spaces"
+	"github.com/rosadsky/ros-project-backend/internal/migrations"
+)
+
+// runMigrate applies pending migrations or lists their state
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	runner := migrations.NewRunner(a.dbService, a.logger)
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(applied))
+		for _, record := range applied {
+			rows = append(rows, []string{fmt.Sprintf("%04d", record.Version), record.Name, fmt.Sprintf("%dms", record.DurationMs)})
+		}
+		if applied == nil {
+			applied = []migrations.Record{}
+		}
+		return a.out.print(applied, []string{"VERSION", "NAME", "DURATION"}, rows)
+
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(statuses))
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{fmt.Sprintf("%04d", status.Version), status.Name, appliedAt})
+		}
+		return a.out.print(statuses, []string{"VERSION", "NAME", "APPLIED AT"}, rows)
+
+	default:
+		return errUsage
+	}
+}
+
+// runSeed creates demo spaces and ambulances
+func runSeed(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	spaces := flags.Int("spaces", 20, "number of demo spaces")
+	ambulances := flags.Int("ambulances", 5, "number of demo ambulances")
+	floors := flags.Int("floors", 4, "number of floors the spaces are spread over")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *spaces < 0 || *ambulances < 0 {
+		return errUsage
+	}
+
+	result, err := a.spaceService().SeedDemoData(ctx, *spaces, *ambulances, *floors)
+	if err != nil {
+		return err
+	}
+	return a.out.print(result, []string{"KIND", "CREATED", "SKIPPED"}, [][]string{
+		{"spaces", strconv.Itoa(result.Spaces), strconv.Itoa(result.SkippedSpaces)},
+		{"ambulances", strconv.Itoa(result.Ambulances), strconv.Itoa(result.SkippedAmbulances)},
+	})
+}
+
+// runImport creates spaces from a CSV or NDJSON file and fails when any row was rejected
+func runImport(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", hospital_spaces.FormatCSV, "file format, csv or ndjson")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+	if *format != hospital_spaces.FormatCSV && *format != hospital_spaces.FormatNDJSON {
+		return errUsage
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := a.spaceService().LoadSpaces(ctx, input, *format, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(result.Errors))
+	for _, rowErr := range result.Errors {
+		message := rowErr.Message
+		for _, field := range rowErr.Fields {
+			message += fmt.Sprintf("; %s %s", field.Field, field.Message)
+		}
+		rows = append(rows, []string{strconv.Itoa(rowErr.Line), rowErr.Code, message})
+	}
+	if err := a.out.print(result, []string{"LINE", "CODE", "ERROR"}, rows); err != nil {
+		return err
+	}
+
+	a.logger.Info().Bool("dry_run", result.DryRun).Int("imported", result.Imported).Int("failed", result.Failed).Msg("Import finished")
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d rows were rejected", result.Failed, result.Failed+result.Imported)
+	}
+	return nil
+}
+
+// runReleaseStale frees occupied spaces whose assignment is older than a duration
+func runReleaseStale(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("release-stale", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 0, "release assignments not changed for this duration, e.g. 72h")
+	assignedType := flags.String("assigned-type", "", "only release spaces assigned to this type, e.g. equipment")
+	dryRun := flags.Bool("dry-run", false, "only count the spaces")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *olderThan <= 0 {
+		return errUsage
+	}
+
+	cutoff := time.Now().Add(-*olderThan)
+	released, err := a.spaceRepository().ReleaseStale(ctx, cutoff, *assignedType, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		DryRun   bool      `json:"dry_run"`
+		Cutoff   time.Time `json:"cutoff"`
+		Released int64     `json:"released"`
+	}{*dryRun, cutoff, released}
+	return a.out.print(result, []string{"CUTOFF", "RELEASED", "DRY RUN"}, [][]string{
+		{cutoff.Format(time.RFC3339), strconv.FormatInt(released, 10), strconv.FormatBool(*dryRun)},
+	})
+}
+
+// runPurgeDeleted removes the tombstones of spaces and ambulances, by default those
+// past the configured retention period
+func runPurgeDeleted(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("purge-deleted", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", a.cfg.Retention.DeletedAfter, "purge records deleted longer ago than this duration")
+	dryRun := flags.Bool("dry-run", false, "only count the records")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *olderThan < 0 {
+		return errUsage
+	}
+
+	result, err := hospital_spaces.PurgeDeleted(ctx, a.dbService, time.Now().Add(-*olderThan), *dryRun)
+	if err != nil {
+		return err
+	}
+	return a.out.print(result, []string{"CUTOFF", "SPACES", "AMBULANCES", "DRY RUN"}, [][]string{
+		{result.Cutoff.Format(time.RFC3339), strconv.FormatInt(result.Spaces, 10), strconv.FormatInt(result.Ambulances, 10), strconv.FormatBool(result.DryRun)},
+	})
+}
+
+// runOccupancy lists space occupancy per floor
+func runOccupancy(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	floors, err := a.spaceRepository().OccupancyByFloor(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(floors))
+	for _, floor := range floors {
+		building := "-"
+		if floor.BuildingID != nil {
+			building = *floor.BuildingID
+		}
+		o := floor.Occupancy
+		rows = append(rows, []string{
+			building, strconv.Itoa(floor.Floor),
+			strconv.Itoa(o.TotalSpaces), strconv.Itoa(o.OccupiedSpaces), strconv.Itoa(o.AvailableSpaces), strconv.Itoa(o.MaintenanceSpaces),
+			fmt.Sprintf("%.0f%%", o.OccupancyRate*100),
+		})
+	}
+	return a.out.print(floors, []string{"BUILDING", "FLOOR", "SPACES", "OCCUPIED", "AVAILABLE", "MAINTENANCE", "RATE"}, rows)
+}
+
+// runRotate re-encrypts spaces with the active encryption key or replaces an API key
+func runRotate(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "encryption-key":
+		if len(args) != 1 {
+			return errUsage
+		}
+		if a.cfg.Security.EncryptionKeyFile == "" {
+			return errors.New("security.encryption_key_file or AMBULANCE_API_ENCRYPTION_KEY_FILE must be set")
+		}
+		keyring, err := fieldcrypt.LoadKeyring(a.cfg.Security.EncryptionKeyFile)
+		if err != nil {
+			return err
+		}
+		updated, err := hospital_spaces.NewSpaceRepository(a.dbService, keyring).ReencryptAssignments(ctx)
+		if err != nil {
+			return err
+		}
+		result := struct {
+			KeyID   string `json:"key_id"`
+			Updated int    `json:"updated"`
+		}{keyring.ActiveKeyID(), updated}
+		return a.out.print(result, []string{"ACTIVE KEY", "UPDATED"}, [][]string{{result.KeyID, strconv.Itoa(updated)}})
+
+	case "api-key":
+		if len(args) != 2 {
+			return errUsage
+		}
+		rotated, err := auth.NewAuthenticator(a.dbService).RotateKey(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return a.out.print(rotated, []string{"KEY ID", "NAME", "ROLE", "SECRET"}, [][]string{
+			{rotated.KeyID, rotated.Name, rotated.Role, rotated.Secret},
+		})
+
+	default:
+		return errUsage
+	}
+}
+
+// spaceService returns a space service for commands that do not touch encrypted fields
+func (a *app) spaceService() *hospital_spaces.SpaceServiceImpl {
+	return hospital_spaces.NewSpaceServiceImpl(a.dbService, nil)
+}
+
+// spaceRepository returns a space repository for commands that do not touch encrypted fields
+func (a *app) spaceRepository() *hospital_spaces.SpaceRepository {
+	return hospital_spaces.NewSpaceRepository(a.dbService, nil)
+}
@@ -0,0 +1,128 @@
+// Command hsctl is the operator CLI of the hospital spaces service. It shares the
+// internal packages of the API server and reads the same configuration file and
+// AMBULANCE_API_* overrides, so it can run as a Kubernetes Job next to the API.
+//
+// Results are printed to stdout as a table or, with -o json, as JSON; logs go to
+// stderr. hsctl exits with 1 when a command fails and with 2 on invalid usage.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/config"
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// errUsage marks invalid command line usage
+var errUsage = errors.New("invalid usage")
+
+// command is a subcommand of hsctl
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, app *app, args []string) error
+}
+
+// commands lists the subcommands in the order they are shown in the usage
+var commands = []command{
+	{"migrate", "migrate up|status", "apply schema migrations or list their state", runMigrate},
+	{"seed", "seed [-spaces n] [-ambulances n] [-floors n]", "create demo spaces and ambulances", runSeed},
+	{"import", "import [-format csv|ndjson] [-dry-run] <file|->", "create spaces from a CSV file with name,type,floor,capacity[,floor_id,wing_id] columns or from NDJSON", runImport},
+	{"release-stale", "release-stale -older-than d [-assigned-type t] [-dry-run]", "free occupied spaces whose assignment was not changed for a duration", runReleaseStale},
+	{"purge-deleted", "purge-deleted [-older-than d] [-dry-run]", "remove deleted spaces and ambulances past the retention period", runPurgeDeleted},
+	{"occupancy", "occupancy", "list space occupancy per floor", runOccupancy},
+	{"rotate", "rotate encryption-key | rotate api-key <key-id>", "re-encrypt spaces with the active key or replace an API key", runRotate},
+}
+
+// app holds what the commands share
+type app struct {
+	cfg       *config.Config
+	logger    zerolog.Logger
+	dbService *db_service.DbService
+	out       *printer
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run executes the command line and returns the exit code
+func run() int {
+	flags := flag.NewFlagSet("hsctl", flag.ContinueOnError)
+	output := flags.String("o", "table", "output format, table or json")
+	timeout := flags.Duration("timeout", 30*time.Minute, "deadline of the command")
+	flags.Usage = func() { printUsage(flags) }
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return 2
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "hsctl: -o must be table or json, got %q\n", *output)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		printUsage(flags)
+		return 2
+	}
+
+	var selected *command
+	for i := range commands {
+		if commands[i].name == flags.Arg(0) {
+			selected = &commands[i]
+		}
+	}
+	if selected == nil {
+		fmt.Fprintf(os.Stderr, "hsctl: unknown command %q\n", flags.Arg(0))
+		printUsage(flags)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hsctl: failed to load configuration: %v\n", err)
+		return 1
+	}
+	logger := logging.NewWithOutput(cfg.Logging, os.Stderr)
+
+	dbOptions := db_service.OptionsFromConfig(cfg.MongoDB)
+	// A command fails right away when the database is unreachable
+	dbOptions.Lazy = false
+	dbService, err := db_service.New(context.Background(), dbOptions)
+	if err != nil {
+		logger.Error().Err(err).Str("uri", logging.RedactURI(dbOptions.URI)).Msg("Failed to connect to database")
+		return 1
+	}
+	defer dbService.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	a := &app{cfg: cfg, logger: logger, dbService: dbService, out: &printer{json: *output == "json", w: os.Stdout}}
+	if err := selected.run(ctx, a, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "usage: hsctl %s\n", selected.usage)
+			return 2
+		}
+		logger.Error().Err(err).Str("command", selected.name).Msg("Command failed")
+		return 1
+	}
+	return 0
+}
+
+// printUsage describes the global flags and the commands
+func printUsage(flags *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "usage: hsctl [-o table|json] [-timeout d] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-60s %s\n", c.usage, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flags.PrintDefaults()
+}
@@ -21,9 +21,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/ambulances": {
+        "/api/admin/api-keys": {
             "get": {
-                "description": "Retrieve a list of all ambulances in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all issued API keys without their secrets. Requires the admin role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -31,32 +36,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ambulances"
+                    "Admin"
                 ],
-                "summary": "Get all ambulances",
+                "summary": "Get all API keys",
                 "responses": {
                     "200": {
-                        "description": "List of ambulances",
+                        "description": "List of API keys",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/hospital_spaces.Ambulance"
+                                "$ref": "#/definitions/auth.APIKey"
                             }
                         }
                     },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Register a new ambulance in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a new API key with the given role. The secret is returned only in this response. Requires the admin role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -64,51 +83,159 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Ambulances"
+                    "Admin"
                 ],
-                "summary": "Create a new ambulance",
+                "summary": "Issue a new API key",
                 "parameters": [
                     {
-                        "description": "Ambulance creation details",
-                        "name": "ambulance",
+                        "description": "API key details",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hospital_spaces.AmbulanceCreateRequest"
+                            "$ref": "#/definitions/auth.APIKeyCreateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Ambulance created successfully",
+                        "description": "API key issued successfully",
                         "schema": {
-                            "$ref": "#/definitions/hospital_spaces.Ambulance"
+                            "$ref": "#/definitions/auth.APIKeyCreateResponse"
                         }
                     },
                     "400": {
                         "description": "Bad request - invalid input",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/spaces": {
+        "/api/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke an API key so that it can no longer be used. Other replicas notice the revocation within 30 seconds. Requires the admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique API key ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "API key revoked successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid key ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/config/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Report the generation of the configuration in effect, when it was loaded and whether the most recent reload succeeded. Settings that require a restart are listed as rejected. Requires the admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get the configuration reload status",
+                "responses": {
+                    "200": {
+                        "description": "Configuration reload status",
+                        "schema": {
+                            "$ref": "#/definitions/config.ReloadStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ambulances": {
             "get": {
-                "description": "Retrieve a list of all hospital spaces with their current status and assignments",
+                "description": "Retrieve a list of all ambulances in the system. Deleted ambulances are only listed for admins with include_deleted=true.",
                 "consumes": [
                     "application/json"
                 ],
@@ -116,32 +243,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Spaces"
+                    "Ambulances"
+                ],
+                "summary": "Get all ambulances",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also list deleted ambulances that were not purged yet (admin role)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all hospital spaces",
                 "responses": {
                     "200": {
-                        "description": "List of hospital spaces",
+                        "description": "List of ambulances",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/hospital_spaces.Space"
+                                "$ref": "#/definitions/hospital_spaces.Ambulance"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad request - invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new hospital space with the specified details",
+                "description": "Register a new ambulance in the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -149,118 +293,141 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Spaces"
+                    "Ambulances"
                 ],
-                "summary": "Create a new hospital space",
+                "summary": "Create a new ambulance",
                 "parameters": [
                     {
-                        "description": "Space creation details",
-                        "name": "space",
+                        "description": "Ambulance creation details",
+                        "name": "ambulance",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hospital_spaces.SpaceCreateRequest"
+                            "$ref": "#/definitions/hospital_spaces.AmbulanceCreateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Space created successfully",
+                        "description": "Ambulance created successfully",
                         "schema": {
-                            "$ref": "#/definitions/hospital_spaces.Space"
+                            "$ref": "#/definitions/hospital_spaces.Ambulance"
                         }
                     },
                     "400": {
                         "description": "Bad request - invalid input",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/spaces/{id}": {
-            "put": {
-                "description": "Update space assignment details such as assigned entity, type, and ID",
-                "consumes": [
-                    "application/json"
+        "/api/ambulances/{id}": {
+            "delete": {
+                "description": "Mark an ambulance as deleted. It is no longer listed and can be restored until it is purged after the retention period.",
+                "tags": [
+                    "Ambulances"
+                ],
+                "summary": "Delete an ambulance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique ambulance ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "204": {
+                        "description": "Ambulance deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ambulance ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Ambulance not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ambulances/{id}/restore": {
+            "post": {
+                "description": "Restore an ambulance that was deleted and not yet purged",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Spaces"
+                    "Ambulances"
                 ],
-                "summary": "Update a hospital space",
+                "summary": "Restore a deleted ambulance",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "The unique space ID (UUID format)",
+                        "description": "The unique ambulance ID (UUID format)",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Space update details",
-                        "name": "space",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/hospital_spaces.SpaceUpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Space updated successfully",
+                        "description": "Ambulance restored successfully",
                         "schema": {
-                            "$ref": "#/definitions/hospital_spaces.Space"
+                            "$ref": "#/definitions/hospital_spaces.Ambulance"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid space ID or input",
+                        "description": "Bad request - invalid ambulance ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
-                        "description": "Space not found",
+                        "description": "Ambulance not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Ambulance is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove a hospital space from the system",
+            }
+        },
+        "/api/ambulances/{id}/status": {
+            "put": {
+                "description": "Move an ambulance along available → dispatched → en_route → arrived → available, or between available and maintenance. Dispatching opens a run that records the time of every transition until the ambulance is available again; returning to available before arrival cancels the run.",
                 "consumes": [
                     "application/json"
                 ],
@@ -268,104 +435,3553 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Spaces"
+                    "Ambulances"
                 ],
-                "summary": "Delete a hospital space",
+                "summary": "Change the status of an ambulance",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "The unique space ID (UUID format)",
+                        "description": "The unique ambulance ID (UUID format)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.AmbulanceStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Space deleted successfully"
+                    "200": {
+                        "description": "Status changed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Ambulance"
+                        }
                     },
                     "400": {
-                        "description": "Bad request - invalid space ID",
+                        "description": "Bad request - invalid ambulance ID or status",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
-                        "description": "Space not found",
+                        "description": "Ambulance not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The ambulance cannot change to the status from its current status",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "hospital_spaces.Ambulance": {
-            "type": "object",
-            "required": [
-                "location",
-                "name",
-                "type"
-            ],
-            "properties": {
-                "ambulance_id": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "location": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
+        },
+        "/api/ambulances:export": {
+            "get": {
+                "description": "Stream all ambulances as CSV with a header row or as NDJSON with one ambulance per line.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Ambulances"
+                ],
+                "summary": "Export ambulances",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ambulances in the requested format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ambulances:import": {
+            "post": {
+                "description": "Create an ambulance for each row of a CSV file with a header row (columns name, type and location) or of an NDJSON file with one ambulance creation object per line. Rows are validated and inserted like space imports; with dry_run=true the rows are only validated.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ambulances"
+                ],
+                "summary": "Import ambulances",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format, defaults to the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV or NDJSON rows",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import summary with the rejected rows",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - unsupported format or unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/buildings": {
+            "get": {
+                "description": "Retrieve a list of all buildings of the hospital facility",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get all buildings",
+                "responses": {
+                    "200": {
+                        "description": "List of buildings",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Building"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new building of the hospital facility",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Create a new building",
+                "parameters": [
+                    {
+                        "description": "Building creation details",
+                        "name": "building",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.BuildingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Building created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Building"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/buildings/{id}": {
+            "get": {
+                "description": "Retrieve a building by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get a building",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique building ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Building details",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Building"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid building ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Building not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, code and address of a building",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Update a building",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique building ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Building update details",
+                        "name": "building",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.BuildingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Building updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Building"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid building ID or input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Building not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a building. Deletion is rejected while floors still belong to the building.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Delete a building",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique building ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Building deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid building ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Building not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Building still has floors",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/departments": {
+            "get": {
+                "description": "Retrieve a list of all hospital departments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Get all departments",
+                "responses": {
+                    "200": {
+                        "description": "List of departments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Department"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new hospital department with its head and contact information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Create a new department",
+                "parameters": [
+                    {
+                        "description": "Department creation details",
+                        "name": "department",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.DepartmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Department created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/departments/{id}": {
+            "get": {
+                "description": "Retrieve a department by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Get a department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique department ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Department details",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid department ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Department not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, code, head and contact information of a department. Spaces owned by the department follow a name change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Update a department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique department ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Department update details",
+                        "name": "department",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.DepartmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Department updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid department ID or input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Department not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a department. Deletion is rejected while spaces are still assigned to the department.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Delete a department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique department ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Department deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid department ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Department not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Department still owns spaces",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/departments/{id}/spaces": {
+            "get": {
+                "description": "Retrieve the current footprint of a department, i.e. all spaces assigned to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Departments"
+                ],
+                "summary": "Get the spaces of a department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique department ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spaces owned by the department",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Space"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid department ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Department not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/equipment": {
+            "get": {
+                "description": "Retrieve the equipment inventory, optionally filtered by category, status, space or maintenance due date",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Get all equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return equipment of this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return equipment with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Only return equipment located in this space",
+                        "name": "space_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Only return equipment with maintenance due before this time (RFC 3339)",
+                        "name": "maintenance_due_before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of equipment",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Equipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new piece of equipment, optionally placing it in a space",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Register new equipment",
+                "parameters": [
+                    {
+                        "description": "Equipment creation details",
+                        "name": "equipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.EquipmentCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Equipment created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Equipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or unknown space",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Serial number already registered",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/equipment/{id}": {
+            "get": {
+                "description": "Retrieve a piece of equipment by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Get equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique equipment ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment details",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Equipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid equipment ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, serial number, category, status and maintenance due date of equipment. Use the move endpoint to change its location.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Update equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique equipment ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Equipment update details",
+                        "name": "equipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.EquipmentUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Equipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid equipment ID or input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Serial number already registered",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a piece of equipment from the inventory. Its location history is kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Delete equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique equipment ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Equipment deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid equipment ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/equipment/{id}/history": {
+            "get": {
+                "description": "Retrieve all recorded moves of a piece of equipment, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Get equipment history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique equipment ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location history",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.EquipmentMovement"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid equipment ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/equipment/{id}/move": {
+            "post": {
+                "description": "Move a piece of equipment to another space, or out of any space when space_id is omitted. Every move is recorded in the equipment history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Move equipment between spaces",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique equipment ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target space and reason",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.EquipmentMoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Equipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid equipment ID, input or unknown space",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment was moved concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/facility/tree": {
+            "get": {
+                "description": "Retrieve all buildings with their floors and wings, each node carrying the aggregated occupancy of the spaces below it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get the facility tree",
+                "responses": {
+                    "200": {
+                        "description": "Facility hierarchy",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.BuildingNode"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/floors": {
+            "get": {
+                "description": "Retrieve a list of floors, optionally limited to a single building",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get all floors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Only return floors of this building",
+                        "name": "building_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of floors",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Floor"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new floor within an existing building",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Create a new floor",
+                "parameters": [
+                    {
+                        "description": "Floor creation details",
+                        "name": "floor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.FloorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Floor created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Floor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or unknown building",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/floors/{id}": {
+            "get": {
+                "description": "Retrieve a floor by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get a floor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique floor ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Floor details",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Floor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid floor ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Floor not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the building, level and name of a floor. Spaces on the floor follow the change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Update a floor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique floor ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Floor update details",
+                        "name": "floor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.FloorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Floor updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Floor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid floor ID, input or unknown building",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Floor not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a floor. Deletion is rejected while spaces or wings still reference the floor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Delete a floor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique floor ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Floor deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid floor ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Floor not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Floor is still referenced by spaces or wings",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/forecast/occupancy": {
+            "get": {
+                "description": "Predict the number of occupied spaces for every hour of the horizon, for one space type or all spaces, with 95% prediction intervals. The prediction for an hour is the average occupancy at the same hour of the week over the last 8 weeks of assignment history, or at the same hour of the day while there is less than two weeks of history; the interval widens with the spread of those hours. Predictions are limited to the number of spaces that exist now.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Forecast space occupancy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Space type, e.g. icu; all spaces when omitted",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "72h",
+                        "description": "Forecast period in whole hours, at most 336h",
+                        "name": "horizon",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Hourly occupancy forecast",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.OccupancyForecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid horizon or less than two days of history",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/patients": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all patient references. The MRN is only returned to the clinician and admin roles, other roles receive the redacted form. Filtering by MRN requires the clinician or admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Get all patient references",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return the patient with this MRN",
+                        "name": "mrn",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of patient references",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Patient"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role to filter by MRN",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Register a patient by the external MRN. A pseudonymous ID is generated for display to roles without access to patient identities. Requires the clinician or admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Register a patient reference",
+                "parameters": [
+                    {
+                        "description": "Patient reference details",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.PatientCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Patient registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Patient"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "MRN already registered",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/patients/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve a patient reference by its ID. The MRN is only returned to the clinician and admin roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Get a patient reference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique patient ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patient reference",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Patient"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid patient ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a patient reference. Deletion is rejected while the patient is placed in a space. Requires the clinician or admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patients"
+                ],
+                "summary": "Delete a patient reference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique patient ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Patient deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid patient ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Patient is still placed in a space",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/ambulances": {
+            "get": {
+                "description": "Report the times from dispatch to en route, from en route to arrival and from arrival until the ambulance is available again (bay turnaround) of the runs dispatched between from and to, grouped by ambulance type or by the hour of the day the run was dispatched in the given timezone. Every phase has its count, mean and nearest-rank 50th, 90th and 95th percentiles in minutes; cancelled runs count towards the phases they completed.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get the ambulance response time report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start of the report period (RFC 3339), not in the future, defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End of the report period (RFC 3339), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "type",
+                            "hour"
+                        ],
+                        "type": "string",
+                        "default": "type",
+                        "description": "Grouping of the runs",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA timezone of the hours of the day",
+                        "name": "timezone",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response and turnaround times per group",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.AmbulanceReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid period, grouping, timezone or format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reports/utilization": {
+            "get": {
+                "description": "Report the utilization of spaces between from and to, grouped by space type, floor number or department, computed from the assignment history. Occupied hours sum the assignment periods within the report period and utilization relates them to the hours the spaces of the group were available; spaces of a type or floor are those that exist now, spaces of a department are those assigned to it in the period. The average stay covers the assignments that ended in the period and the peak is the highest number of spaces of the group occupied at the same time. Type and floor are those of the space when an assignment started.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get the space utilization report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start of the report period (RFC 3339), not in the future, defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End of the report period (RFC 3339), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "type",
+                            "floor",
+                            "department"
+                        ],
+                        "type": "string",
+                        "default": "type",
+                        "description": "Grouping of the spaces",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Utilization per group",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.UtilizationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid period, grouping or format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces": {
+            "get": {
+                "description": "Retrieve a list of all hospital spaces with their current status and assignments. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles. Deleted spaces are only listed for admins with include_deleted=true. With as_of the spaces are listed as they were at that time; names and locations are the current ones and a space that stopped being occupied between snapshots is reported as available.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Get all hospital spaces",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also list deleted spaces that were not purged yet (admin role)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "List the spaces with their status and assignment at this time (RFC 3339), reconstructed from the nearest occupancy snapshot and the assignment history",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of hospital spaces",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Space"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid query parameter or no snapshot kept for as_of",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted requires the admin role",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new hospital space with the specified details. When floor_id or wing_id is given the space is placed in the facility hierarchy and its floor number follows the referenced floor; floor is required otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Create a new hospital space",
+                "parameters": [
+                    {
+                        "description": "Space creation details",
+                        "name": "space",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Space created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Space"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input, floor or wing",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces/summary": {
+            "get": {
+                "description": "Count the spaces by status and sum their total and occupied capacity, in total and broken down by space type and by floor number. Deleted spaces are not counted. The summary is computed at most every few seconds, generated_at tells when.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Get the occupancy summary of all spaces",
+                "responses": {
+                    "200": {
+                        "description": "Occupancy summary",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceSummary"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces/{id}": {
+            "put": {
+                "description": "Update space assignment details such as assigned entity, type, and ID. Every assignment has an assigned_type of patient, ambulance, equipment or department and references an existing entity by assigned_id; assigned_to is filled in from the entity, free text is rejected. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Update a hospital space",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique space ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Space update details",
+                        "name": "space",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Space updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Space"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid space ID, input or assigned entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Space not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The space was changed or deleted concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Mark a hospital space as deleted. It keeps its assignment, is no longer listed and can be restored until it is purged after the retention period. A space where equipment is located cannot be deleted until the equipment is moved out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Delete a hospital space",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique space ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Space deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid space ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Space not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment is located in the space",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces/{id}/equipment": {
+            "get": {
+                "description": "Retrieve all equipment currently located in a space",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Get the equipment of a space",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique space ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment in the space",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Equipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid space ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Space not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces/{id}/location": {
+            "put": {
+                "description": "Reference a space from a floor and optionally a wing of that floor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Move a space to a floor and wing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique space ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target floor and wing",
+                        "name": "location",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceLocationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Space moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Space"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid space ID, input, floor or wing",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Space not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The space was changed or deleted concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces/{id}/restore": {
+            "post": {
+                "description": "Restore a space that was deleted and not yet purged, with its assignment. A space whose floor or wing was deleted in the meantime is restored outside the facility hierarchy, and a space whose assignee was deleted is restored available.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Restore a deleted hospital space",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique space ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Space restored successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Space"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid space ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Space not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Space is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces:batch": {
+            "post": {
+                "description": "Apply up to 200 assign, release, maintenance and delete operations in request order, e.g. at a shift change. Assign operations take the fields of a space update and are validated like it, release frees the space, maintenance takes it out of service and delete marks it as deleted like the delete endpoint. In best_effort mode (the default) each operation is applied on its own. In atomic mode the operations run in a MongoDB transaction, so the first failing operation rolls back the whole batch and the other operations are reported with code batch_aborted; this mode requires MongoDB to run as a replica set. Every operation gets a result with the status the single-item endpoint would have returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Apply operations to many hospital spaces",
+                "parameters": [
+                    {
+                        "description": "Operations and mode",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of every operation",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.SpaceBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid operations, or atomic mode without transaction support",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces:export": {
+            "get": {
+                "description": "Stream all spaces as CSV with a header row or as NDJSON with one space per line. The CSV columns include those of the import, so that an export can be imported elsewhere. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Export hospital spaces",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spaces in the requested format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spaces:import": {
+            "post": {
+                "description": "Create a space for each row of a CSV file with a header row (columns name, type, floor, capacity and optionally floor_id and wing_id) or of an NDJSON file with one space creation object per line. The format is taken from the format query parameter or the Content-Type header. Every row is validated like a single space creation and rows are inserted in unordered batches, so invalid rows are reported with their line number without stopping the import. With dry_run=true the rows are only validated.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Spaces"
+                ],
+                "summary": "Import hospital spaces",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format, defaults to the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV or NDJSON rows",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import summary with the rejected rows",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - unsupported format or unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wings": {
+            "get": {
+                "description": "Retrieve a list of wings, optionally limited to a single floor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get all wings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Only return wings of this floor",
+                        "name": "floor_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of wings",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hospital_spaces.Wing"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a new wing on an existing floor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Create a new wing",
+                "parameters": [
+                    {
+                        "description": "Wing creation details",
+                        "name": "wing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.WingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Wing created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Wing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input or unknown floor",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/wings/{id}": {
+            "get": {
+                "description": "Retrieve a wing by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Get a wing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique wing ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Wing details",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Wing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid wing ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Wing not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the floor and name of a wing. Spaces in the wing move to the new floor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Update a wing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique wing ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wing update details",
+                        "name": "wing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.WingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Wing updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/hospital_spaces.Wing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid wing ID, input or unknown floor",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Wing not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a wing. Deletion is rejected while spaces still reference the wing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Facility"
+                ],
+                "summary": "Delete a wing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The unique wing ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Wing deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid wing ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Wing not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Wing is still referenced by spaces",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz/live": {
+            "get": {
+                "description": "Report that the API process is running and serving requests. Does not check dependencies, a failing database must not restart the pod.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Service is alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Liveness"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz/ready": {
+            "get": {
+                "description": "Check that MongoDB answers a ping, that no migrations are pending and that the unique indexes the migrations create exist. The result is cached for a short time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Service is ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Readiness"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.APIKeyCreateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "staff",
+                        "clinician",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "auth.APIKeyCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.ReloadStatus": {
+            "type": "object",
+            "properties": {
+                "generation": {
+                    "type": "integer"
+                },
+                "last_attempt": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_success": {
+                    "type": "string"
+                },
+                "last_trigger": {
+                    "type": "string"
+                },
+                "loaded_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "rejected": {
+                    "description": "Rejected lists the settings of the last successful reload that require a restart and were not applied",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Liveness": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Readiness": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.Ambulance": {
+            "type": "object",
+            "required": [
+                "location",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "ambulance_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt marks a deleted ambulance that can still be restored, see Space.DeletedAt",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_changed_at": {
+                    "description": "StatusChangedAt is when the ambulance changed to its current status",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.AmbulanceCreateRequest": {
+            "type": "object",
+            "required": [
+                "location",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.AmbulanceReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.AmbulanceReportGroup"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.AmbulanceReportGroup": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "integer"
+                },
+                "dispatch_to_en_route": {
+                    "description": "DispatchToEnRoute is the time from dispatch until the ambulance was en route",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/hospital_spaces.DurationStats"
+                        }
+                    ]
+                },
+                "en_route_to_arrival": {
+                    "description": "EnRouteToArrival is the time from en route until arrival",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/hospital_spaces.DurationStats"
+                        }
+                    ]
+                },
+                "key": {
+                    "description": "Key is the ambulance type or the hour of the day (0-23)",
+                    "type": "string"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "turnaround": {
+                    "description": "Turnaround is the time from arrival until the ambulance was available again",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/hospital_spaces.DurationStats"
+                        }
+                    ]
+                }
+            }
+        },
+        "hospital_spaces.AmbulanceStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "available",
+                        "dispatched",
+                        "en_route",
+                        "arrived",
+                        "maintenance"
+                    ]
+                }
+            }
+        },
+        "hospital_spaces.Building": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "building_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.BuildingNode": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "building_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "floors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.FloorNode"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occupancy": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.BuildingRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.Department": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "contact": {
+                    "$ref": "#/definitions/hospital_spaces.DepartmentContact"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "head": {
+                    "$ref": "#/definitions/hospital_spaces.DepartmentHead"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.DepartmentContact": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.DepartmentHead": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.DepartmentRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "contact": {
+                    "$ref": "#/definitions/hospital_spaces.DepartmentContact"
+                },
+                "head": {
+                    "$ref": "#/definitions/hospital_spaces.DepartmentHead"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.DurationStats": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "mean_minutes": {
+                    "type": "number"
+                },
+                "p50_minutes": {
+                    "type": "number"
+                },
+                "p90_minutes": {
+                    "type": "number"
+                },
+                "p95_minutes": {
+                    "type": "number"
+                }
+            }
+        },
+        "hospital_spaces.Equipment": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "equipment_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "maintenance_due_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.EquipmentCreateRequest": {
+            "type": "object",
+            "required": [
+                "category",
+                "name",
+                "serial_number"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "ventilator",
+                        "monitor",
+                        "bed",
+                        "infusion_pump",
+                        "defibrillator",
+                        "wheelchair",
+                        "other"
+                    ]
+                },
+                "maintenance_due_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.EquipmentMoveRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "space_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.EquipmentMovement": {
+            "type": "object",
+            "properties": {
+                "equipment_id": {
+                    "type": "string"
+                },
+                "from_space_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "moved_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_space_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.EquipmentUpdateRequest": {
+            "type": "object",
+            "required": [
+                "category",
+                "name",
+                "serial_number",
+                "status"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "ventilator",
+                        "monitor",
+                        "bed",
+                        "infusion_pump",
+                        "defibrillator",
+                        "wheelchair",
+                        "other"
+                    ]
+                },
+                "maintenance_due_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string"
+                },
                 "status": {
+                    "type": "string",
+                    "enum": [
+                        "available",
+                        "in_use",
+                        "maintenance",
+                        "retired"
+                    ]
+                }
+            }
+        },
+        "hospital_spaces.Floor": {
+            "type": "object",
+            "properties": {
+                "building_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "floor_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.FloorNode": {
+            "type": "object",
+            "properties": {
+                "building_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "floor_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occupancy": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.WingNode"
+                    }
+                }
+            }
+        },
+        "hospital_spaces.FloorOccupancy": {
+            "type": "object",
+            "properties": {
+                "building_id": {
+                    "type": "string"
+                },
+                "floor": {
+                    "type": "integer"
+                },
+                "occupancy": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
+                }
+            }
+        },
+        "hospital_spaces.FloorRequest": {
+            "type": "object",
+            "required": [
+                "building_id",
+                "name"
+            ],
+            "properties": {
+                "building_id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.ForecastPoint": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "lower": {
+                    "type": "number"
+                },
+                "predicted": {
+                    "type": "number"
+                },
+                "upper": {
+                    "type": "number"
+                }
+            }
+        },
+        "hospital_spaces.ImportError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.ImportResult": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hospital_spaces.Occupancy": {
+            "type": "object",
+            "properties": {
+                "available_spaces": {
+                    "type": "integer"
+                },
+                "maintenance_spaces": {
+                    "type": "integer"
+                },
+                "occupancy_rate": {
+                    "type": "number"
+                },
+                "occupied_capacity": {
+                    "type": "integer"
+                },
+                "occupied_spaces": {
+                    "type": "integer"
+                },
+                "total_capacity": {
+                    "type": "integer"
+                },
+                "total_spaces": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hospital_spaces.OccupancyForecast": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "description": "Confidence is the coverage of the prediction intervals",
+                    "type": "number"
+                },
+                "history_from": {
+                    "description": "HistoryFrom is the start of the hourly occupancy the forecast is computed from",
+                    "type": "string"
+                },
+                "history_hours": {
+                    "type": "integer"
+                },
+                "model": {
                     "type": "string"
                 },
-                "type": {
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.ForecastPoint"
+                    }
+                },
+                "season": {
                     "type": "string"
                 },
-                "updated_at": {
+                "spaces": {
+                    "description": "Spaces is the number of spaces that exist now, predictions do not exceed it",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is the space type forecast, all spaces when it is not set",
                     "type": "string"
                 }
             }
         },
-        "hospital_spaces.AmbulanceCreateRequest": {
+        "hospital_spaces.Patient": {
             "type": "object",
-            "required": [
-                "location",
-                "name",
-                "type"
-            ],
             "properties": {
-                "location": {
+                "created_at": {
                     "type": "string"
                 },
-                "name": {
+                "id": {
                     "type": "string"
                 },
-                "type": {
+                "mrn": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "pseudonym": {
+                    "type": "string"
+                },
+                "redacted": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.PatientCreateRequest": {
+            "type": "object",
+            "required": [
+                "mrn"
+            ],
+            "properties": {
+                "mrn": {
                     "type": "string"
                 }
             }
@@ -388,15 +4004,25 @@ const docTemplate = `{
                 "assigned_type": {
                     "type": "string"
                 },
+                "building_id": {
+                    "type": "string"
+                },
                 "capacity": {
                     "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt marks a deleted space that can still be restored, it is stored as\nnull for live spaces so that the partial unique indexes cover them",
+                    "type": "string"
+                },
                 "floor": {
                     "type": "integer"
                 },
+                "floor_id": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -414,6 +4040,132 @@ const docTemplate = `{
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented on every change of the space",
+                    "type": "integer"
+                },
+                "wing_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.SpaceBatchError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.SpaceBatchOperation": {
+            "type": "object",
+            "required": [
+                "op",
+                "space_id"
+            ],
+            "properties": {
+                "assigned_id": {
+                    "type": "string"
+                },
+                "assigned_to": {
+                    "type": "string"
+                },
+                "assigned_type": {
+                    "type": "string",
+                    "enum": [
+                        "patient",
+                        "ambulance",
+                        "equipment",
+                        "department"
+                    ]
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "assign",
+                        "release",
+                        "maintenance",
+                        "delete"
+                    ]
+                },
+                "space_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.SpaceBatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.SpaceBatchOperation"
+                    }
+                }
+            }
+        },
+        "hospital_spaces.SpaceBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.SpaceBatchResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hospital_spaces.SpaceBatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/hospital_spaces.SpaceBatchError"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "space": {
+                    "$ref": "#/definitions/hospital_spaces.Space"
+                },
+                "space_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
                 }
             }
         },
@@ -421,7 +4173,6 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "capacity",
-                "floor",
                 "name",
                 "type"
             ],
@@ -430,13 +4181,59 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "floor": {
+                    "description": "Floor is the floor number, required unless floor_id or wing_id places the space",
                     "type": "integer"
                 },
+                "floor_id": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
                 "type": {
                     "type": "string"
+                },
+                "wing_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.SpaceLocationRequest": {
+            "type": "object",
+            "required": [
+                "floor_id"
+            ],
+            "properties": {
+                "floor_id": {
+                    "type": "string"
+                },
+                "wing_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.SpaceSummary": {
+            "type": "object",
+            "properties": {
+                "by_floor": {
+                    "description": "ByFloor is ordered by building and floor number, the spaces outside the\nfacility hierarchy come first, without a building",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.FloorOccupancy"
+                    }
+                },
+                "by_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.TypeOccupancy"
+                    }
+                },
+                "generated_at": {
+                    "description": "GeneratedAt is when the summary was computed, it may be cached for a few seconds",
+                    "type": "string"
+                },
+                "total": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
                 }
             }
         },
@@ -450,9 +4247,191 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "assigned_type": {
+                    "type": "string",
+                    "enum": [
+                        "patient",
+                        "ambulance",
+                        "equipment",
+                        "department"
+                    ]
+                }
+            }
+        },
+        "hospital_spaces.TypeOccupancy": {
+            "type": "object",
+            "properties": {
+                "occupancy": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.UtilizationGroup": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "description": "Assignments is the number of assignment periods overlapping the report period",
+                    "type": "integer"
+                },
+                "average_stay_hours": {
+                    "description": "AverageStayHours is the mean length of the assignments that ended in the report period",
+                    "type": "number"
+                },
+                "key": {
+                    "description": "Key is the space type, the floor number or the department ID",
+                    "type": "string"
+                },
+                "occupied_hours": {
+                    "type": "number"
+                },
+                "peak_concurrent": {
+                    "description": "PeakConcurrent is the highest number of spaces of the group occupied at the same time",
+                    "type": "integer"
+                },
+                "spaces": {
+                    "description": "Spaces is the number of spaces utilization is measured against",
+                    "type": "integer"
+                },
+                "utilization_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "hospital_spaces.UtilizationReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hospital_spaces.UtilizationGroup"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.Wing": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "floor_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wing_id": {
                     "type": "string"
                 }
             }
+        },
+        "hospital_spaces.WingNode": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "floor_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "occupancy": {
+                    "$ref": "#/definitions/hospital_spaces.Occupancy"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wing_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "hospital_spaces.WingRequest": {
+            "type": "object",
+            "required": [
+                "floor_id",
+                "name"
+            ],
+            "properties": {
+                "floor_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	collectionAPIKeys = "api_keys"
+
+	// HeaderAPIKey is the request header carrying the API key secret
+	HeaderAPIKey = "X-API-Key"
+
+	principalContextKey = "auth.principal"
+	cacheTTL            = 30 * time.Second
+)
+
+// ErrKeyNotFound is returned for an unknown or already revoked API key
+var ErrKeyNotFound = errors.New("API key not found")
+
+// cacheEntry is a cached API key lookup; a nil principal marks an unknown or revoked key
+type cacheEntry struct {
+	principal *Principal
+	expiresAt time.Time
+}
+
+// Authenticator resolves the caller of a request from its API key
+type Authenticator struct {
+	dbService *db_service.DbService
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewAuthenticator creates a new authenticator backed by the api_keys collection
+func NewAuthenticator(dbService *db_service.DbService) *Authenticator {
+	return &Authenticator{
+		dbService: dbService,
+		cache:     map[string]cacheEntry{},
+	}
+}
+
+// Middleware resolves the principal of each request. Requests without an API key
+// continue as anonymous, requests with an unknown or revoked key are rejected.
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := c.GetHeader(HeaderAPIKey)
+		if secret == "" {
+			c.Set(principalContextKey, Principal{Role: RoleAnonymous})
+			c.Next()
+			return
+		}
+
+		principal, err := a.lookup(c.Request.Context(), secret)
+		if err != nil {
+			problem.Write(c, problem.Unavailable("api_key_verification_failed", "Failed to verify API key", err))
+			return
+		}
+		if principal == nil {
+			problem.Write(c, problem.Unauthorized("invalid_api_key", "Invalid API key"))
+			return
+		}
+
+		c.Set(principalContextKey, *principal)
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose principal does not have one of the given roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := FromContext(c)
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+		if principal.Role == RoleAnonymous {
+			problem.Write(c, problem.Unauthorized("api_key_required", "API key required"))
+			return
+		}
+		problem.Write(c, problem.Forbidden("insufficient_role", "Insufficient role"))
+	}
+}
+
+// FromContext returns the principal resolved for the request
+func FromContext(c *gin.Context) Principal {
+	if value, ok := c.Get(principalContextKey); ok {
+		if principal, ok := value.(Principal); ok {
+			return principal
+		}
+	}
+	return Principal{Role: RoleAnonymous}
+}
+
+// EnsureBootstrapKey makes sure an admin key with the given secret exists, so that
+// the first keys can be issued through the admin API
+func (a *Authenticator) EnsureBootstrapKey(secret string) error {
+	key, _, err := NewAPIKey("bootstrap-admin", RoleAdmin)
+	if err != nil {
+		return err
+	}
+	key.KeyHash = HashSecret(secret)
+
+	ctx, cancel := a.dbService.CreateContext()
+	defer cancel()
+
+	_, err = a.dbService.GetCollection(collectionAPIKeys).UpdateOne(ctx,
+		bson.M{"key_hash": key.KeyHash},
+		bson.M{"$setOnInsert": key},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// RotateKey issues a new key with the name and role of an active key and revokes
+// the old one. The secret of the new key is only returned here.
+func (a *Authenticator) RotateKey(ctx context.Context, keyID string) (*APIKeyCreateResponse, error) {
+	ctx, cancel := a.dbService.WriteContext(ctx)
+	defer cancel()
+
+	collection := a.dbService.GetCollection(collectionAPIKeys)
+	var current APIKey
+	err := collection.FindOne(ctx, bson.M{"key_id": keyID, "revoked_at": bson.M{"$exists": false}}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, secret, err := NewAPIKey(current.Name, current.Role)
+	if err != nil {
+		return nil, err
+	}
+	result, err := collection.InsertOne(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Revoke only after the replacement exists, so that a failure never leaves the caller without a key
+	if _, err := collection.UpdateOne(ctx, bson.M{"key_id": keyID}, bson.M{"$set": bson.M{"revoked_at": time.Now()}}); err != nil {
+		return nil, err
+	}
+	a.invalidate()
+
+	return &APIKeyCreateResponse{APIKey: *key, Secret: secret}, nil
+}
+
+// lookup resolves a secret to its principal, returning nil for unknown or revoked keys
+func (a *Authenticator) lookup(ctx context.Context, secret string) (*Principal, error) {
+	hash := HashSecret(secret)
+	now := time.Now()
+
+	a.mu.Lock()
+	entry, ok := a.cache[hash]
+	a.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.principal, nil
+	}
+
+	ctx, cancel := a.dbService.ReadContext(ctx)
+	defer cancel()
+
+	var key APIKey
+	err := a.dbService.GetCollection(collectionAPIKeys).FindOne(ctx, bson.M{"key_hash": hash}).Decode(&key)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	var principal *Principal
+	if err == nil && key.RevokedAt == nil {
+		principal = &Principal{KeyID: key.KeyID, Name: key.Name, Role: key.Role}
+	}
+
+	a.mu.Lock()
+	a.cache[hash] = cacheEntry{principal: principal, expiresAt: now.Add(cacheTTL)}
+	a.mu.Unlock()
+
+	return principal, nil
+}
+
+// invalidate drops all cached lookups, e.g. after a key was revoked
+func (a *Authenticator) invalidate() {
+	a.mu.Lock()
+	a.cache = map[string]cacheEntry{}
+	a.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RegisterRoutes registers the admin API key routes
+func (a *Authenticator) RegisterRoutes(engine *gin.Engine) {
+	keys := engine.Group("/api/admin/api-keys", RequireRole(RoleAdmin))
+	{
+		keys.POST("", a.CreateAPIKey)
+		keys.GET("", a.GetAPIKeys)
+		keys.DELETE("/:id", a.RevokeAPIKey)
+	}
+}
+
+// CreateAPIKey issues a new API key
+// @Summary Issue a new API key
+// @Description Issue a new API key with the given role. The secret is returned only in this response. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param key body APIKeyCreateRequest true "API key details"
+// @Success 201 {object} APIKeyCreateResponse "API key issued successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/admin/api-keys [post]
+func (a *Authenticator) CreateAPIKey(c *gin.Context) {
+	var request APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	key, secret, err := NewAPIKey(request.Name, request.Role)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to generate API key"))
+		return
+	}
+
+	ctx, cancel := a.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	result, err := a.dbService.GetCollection(collectionAPIKeys).InsertOne(ctx, key)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create API key"))
+		return
+	}
+
+	key.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, APIKeyCreateResponse{APIKey: *key, Secret: secret})
+}
+
+// GetAPIKeys lists all API keys
+// @Summary Get all API keys
+// @Description Retrieve all issued API keys without their secrets. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} APIKey "List of API keys"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/admin/api-keys [get]
+func (a *Authenticator) GetAPIKeys(c *gin.Context) {
+	ctx, cancel := a.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := a.dbService.GetCollection(collectionAPIKeys).Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve API keys"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	keys := []APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode API keys"))
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key
+// @Summary Revoke an API key
+// @Description Revoke an API key so that it can no longer be used. Other replicas notice the revocation within 30 seconds. Requires the admin role.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The unique API key ID (UUID format)" format(uuid)
+// @Success 204 "API key revoked successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid key ID"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Failure 404 {object} problem.Problem "API key not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/admin/api-keys/{id} [delete]
+func (a *Authenticator) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param("id")
+	if _, err := uuid.Parse(keyID); err != nil {
+		problem.Write(c, problem.Validation("invalid_api_key_id", "Invalid API key ID"))
+		return
+	}
+
+	ctx, cancel := a.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	filter := bson.M{"key_id": keyID, "revoked_at": bson.M{"$exists": false}}
+	result, err := a.dbService.GetCollection(collectionAPIKeys).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to revoke API key"))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		problem.Write(c, problem.NotFound("api_key_not_found", "API key not found"))
+		return
+	}
+
+	a.invalidate()
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles that can be granted to an API key
+const (
+	RoleAnonymous = "anonymous"
+	RoleStaff     = "staff"
+	RoleClinician = "clinician"
+	RoleAdmin     = "admin"
+)
+
+// keyPrefix marks secrets issued by this service
+const keyPrefix = "hs_"
+
+// APIKey represents a stored API key. Only the SHA-256 hash of the secret is persisted.
+type APIKey struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	KeyID     string             `json:"key_id" bson:"key_id"`
+	Name      string             `json:"name" bson:"name"`
+	Role      string             `json:"role" bson:"role"`
+	KeyHash   string             `json:"-" bson:"key_hash"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// APIKeyCreateRequest represents the request for issuing a new API key
+type APIKeyCreateRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"required,oneof=staff clinician admin"`
+}
+
+// APIKeyCreateResponse carries the secret of a newly issued key. The secret is shown only once.
+type APIKeyCreateResponse struct {
+	APIKey
+	Secret string `json:"secret"`
+}
+
+// Principal is the caller identity resolved from a request
+type Principal struct {
+	KeyID string `json:"key_id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Role  string `json:"role"`
+}
+
+// NewAPIKey creates a new API key for the given name and role and returns it with its secret
+func NewAPIKey(name, role string) (*APIKey, string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	return &APIKey{
+		KeyID:     uuid.New().String(),
+		Name:      name,
+		Role:      role,
+		KeyHash:   HashSecret(secret),
+		CreatedAt: time.Now(),
+	}, secret, nil
+}
+
+// HashSecret returns the hex encoded SHA-256 hash of an API key secret
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CanViewPatientIdentity reports whether a role may see identifying patient data
+func CanViewPatientIdentity(role string) bool {
+	return role == RoleClinician || role == RoleAdmin
+}
+
+// generateSecret returns a new random API key secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,530 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultPath is the configuration file used when AMBULANCE_API_CONFIG is not set
+	DefaultPath = "config/config.yaml"
+
+	envPrefix = "AMBULANCE_API_"
+)
+
+// Config is the typed configuration of the service
+type Config struct {
+	Environment string           `yaml:"environment"`
+	Server      ServerConfig     `yaml:"server"`
+	Logging     LoggingConfig    `yaml:"logging"`
+	CORS        CORSConfig       `yaml:"cors"`
+	MongoDB     MongoConfig      `yaml:"mongodb"`
+	Security    SecurityConfig   `yaml:"security"`
+	Reload      ReloadConfig     `yaml:"reload"`
+	Health      HealthConfig     `yaml:"health"`
+	Metrics     MetricsConfig    `yaml:"metrics"`
+	Tracing     TracingConfig    `yaml:"tracing"`
+	Limits      LimitsConfig     `yaml:"limits"`
+	Migrations  MigrationsConfig `yaml:"migrations"`
+	Retention   RetentionConfig  `yaml:"retention"`
+	Snapshots   SnapshotsConfig  `yaml:"snapshots"`
+	// Features holds feature flags by name, missing flags are disabled. No code
+	// reads a flag yet, the section is only parsed and reloaded
+	Features map[string]bool `yaml:"features"`
+
+	// path is the file the configuration was read from, empty when no file was used
+	path string
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// MaxRequestTimeout caps the deadline a client can request with the X-Request-Timeout header
+	MaxRequestTimeout time.Duration `yaml:"max_request_timeout"`
+}
+
+// LoggingConfig configures the logger
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// CORSConfig configures cross-origin requests
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+// MongoConfig configures the MongoDB connection. Credentials are expected
+// to come from the environment rather than from the configuration file.
+type MongoConfig struct {
+	URI      string `yaml:"uri"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Database string `yaml:"database"`
+	// Timeout bounds a single database operation, it is the default for the
+	// read, write and aggregation timeouts
+	Timeout          time.Duration `yaml:"timeout"`
+	ReadTimeout      time.Duration `yaml:"read_timeout"`
+	WriteTimeout     time.Duration `yaml:"write_timeout"`
+	AggregateTimeout time.Duration `yaml:"aggregate_timeout"`
+	// ConnectTimeout bounds establishing the connection at startup
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	MaxPoolSize    int           `yaml:"max_pool_size"`
+	MinPoolSize    int           `yaml:"min_pool_size"`
+	// LazyConnect starts the API server without waiting for MongoDB, which it
+	// connects to on first use; readiness fails until the database is reachable
+	LazyConnect bool `yaml:"lazy_connect"`
+}
+
+// SecurityConfig configures API keys and field encryption
+type SecurityConfig struct {
+	EncryptionKeyFile string `yaml:"encryption_key_file"`
+	AdminKey          string `yaml:"admin_key"`
+}
+
+// ReloadConfig configures how configuration changes are picked up at runtime
+type ReloadConfig struct {
+	// WatchInterval is how often the configuration file is checked for changes, zero disables watching
+	WatchInterval time.Duration `yaml:"watch_interval"`
+}
+
+// HealthConfig configures the readiness probe
+type HealthConfig struct {
+	// PingTimeout bounds the MongoDB ping of a readiness check
+	PingTimeout time.Duration `yaml:"ping_timeout"`
+	// CacheTTL is how long a readiness result is reused before the checks run again
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// MigrationsConfig configures how the API server applies schema migrations
+type MigrationsConfig struct {
+	// AutoApply runs pending migrations at startup, otherwise they are applied with hsctl migrate up
+	AutoApply bool `yaml:"auto_apply"`
+	// Timeout bounds applying migrations at startup, including waiting for another replica to finish
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// RetentionConfig configures how long deleted spaces and ambulances are kept
+type RetentionConfig struct {
+	// DeletedAfter is how long a deleted space or ambulance can be restored before it is purged
+	DeletedAfter time.Duration `yaml:"deleted_after"`
+	// PurgeInterval is how often the API server purges expired tombstones, zero leaves purging to hsctl purge-deleted
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// SnapshotsConfig configures the occupancy snapshots behind point-in-time space queries
+type SnapshotsConfig struct {
+	// Interval is how often the API server snapshots the occupancy of the spaces, zero disables snapshots
+	Interval time.Duration `yaml:"interval"`
+	// Retention is how long snapshots are kept, spaces cannot be queried further back.
+	// It must not exceed Retention.DeletedAfter, the spaces deleted since are purged then.
+	Retention time.Duration `yaml:"retention"`
+}
+
+// MetricsConfig configures the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RefreshInterval is how often the space and ambulance gauges are recomputed
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// TracingConfig configures OpenTelemetry tracing
+type TracingConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	ServiceName string `yaml:"service_name"`
+	// Exporter is otlp, stdout or file
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector, OTEL_EXPORTER_OTLP_ENDPOINT is used when empty
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// FilePath is the file the file exporter appends spans to
+	FilePath string `yaml:"file_path"`
+	// SampleRatio is the share of new traces that are recorded, between 0 and 1
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// LimitsConfig configures rate limiting and request body sizes. Requests are
+// limited per client, identified by API key or, for anonymous callers, by IP.
+type LimitsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Backend keeps the token buckets in memory or in MongoDB, which shares them between replicas
+	Backend string `yaml:"backend"`
+	// Default applies to requests that match none of the routes
+	Default LimitRule `yaml:"default"`
+	// Routes override the default for route groups, the first matching rule applies
+	Routes []LimitRule `yaml:"routes"`
+}
+
+// LimitRule is a token bucket and body size limit for a group of routes
+type LimitRule struct {
+	Name       string `yaml:"name"`
+	PathPrefix string `yaml:"path_prefix"`
+	// Methods restricts the rule to the given HTTP methods, all methods match when empty
+	Methods           []string `yaml:"methods"`
+	RequestsPerSecond float64  `yaml:"requests_per_second"`
+	Burst             int      `yaml:"burst"`
+	MaxBodyBytes      int64    `yaml:"max_body_bytes"`
+}
+
+// Default returns the configuration used for values missing from the file and the environment
+func Default() *Config {
+	return &Config{
+		Environment: "development",
+		Server: ServerConfig{
+			Host:              "0.0.0.0",
+			Port:              8080,
+			MaxRequestTimeout: 60 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3333"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization", "X-API-Key", "X-Request-Timeout", "X-Request-ID", "traceparent", "tracestate"},
+			AllowCredentials: true,
+		},
+		MongoDB: MongoConfig{
+			Host:           "localhost",
+			Port:           27017,
+			Database:       "hospital-spaces",
+			Timeout:        10 * time.Second,
+			ConnectTimeout: 10 * time.Second,
+			MaxPoolSize:    100,
+		},
+		Reload: ReloadConfig{
+			WatchInterval: 10 * time.Second,
+		},
+		Health: HealthConfig{
+			PingTimeout: time.Second,
+			CacheTTL:    2 * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Enabled:         true,
+			RefreshInterval: 30 * time.Second,
+		},
+		Tracing: TracingConfig{
+			ServiceName: "hospital-spaces-api",
+			Exporter:    "otlp",
+			SampleRatio: 1,
+		},
+		Limits: LimitsConfig{
+			Enabled: true,
+			Backend: "memory",
+			Default: LimitRule{
+				Name:              "default",
+				RequestsPerSecond: 20,
+				Burst:             40,
+				MaxBodyBytes:      1 << 20,
+			},
+		},
+		Migrations: MigrationsConfig{
+			AutoApply: true,
+			Timeout:   2 * time.Minute,
+		},
+		Retention: RetentionConfig{
+			DeletedAfter:  30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Snapshots: SnapshotsConfig{
+			Interval:  15 * time.Minute,
+			Retention: 30 * 24 * time.Hour,
+		},
+	}
+}
+
+// Load reads the configuration file, applies AMBULANCE_API_* environment overrides
+// and validates the result. The file is taken from AMBULANCE_API_CONFIG, falling back
+// to DefaultPath; a missing default file is not an error so that the service can run
+// from environment variables alone.
+func Load() (*Config, error) {
+	path, explicit := os.LookupEnv(envPrefix + "CONFIG")
+	if !explicit {
+		path = DefaultPath
+	}
+
+	cfg := Default()
+	if err := cfg.readFile(path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// readFile merges the YAML file at path into the configuration
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	c.path = path
+	return nil
+}
+
+// applyEnv overrides configuration values with AMBULANCE_API_* environment variables
+func (c *Config) applyEnv() error {
+	var errs []error
+	setString := func(name string, target *string) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			*target = value
+		}
+	}
+	setInt := func(name string, target *int) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s must be an integer", envPrefix, name))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setBool := func(name string, target *bool) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s must be a boolean", envPrefix, name))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setList := func(name string, target *[]string) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			*target = splitList(value)
+		}
+	}
+
+	setString("ENVIRONMENT", &c.Environment)
+	setString("HOST", &c.Server.Host)
+	setInt("PORT", &c.Server.Port)
+	setString("LOG_LEVEL", &c.Logging.Level)
+	setString("LOG_FORMAT", &c.Logging.Format)
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	setList("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	setList("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	setString("MONGODB_URI", &c.MongoDB.URI)
+	setString("MONGODB_HOST", &c.MongoDB.Host)
+	setInt("MONGODB_PORT", &c.MongoDB.Port)
+	setString("MONGODB_USERNAME", &c.MongoDB.Username)
+	setString("MONGODB_PASSWORD", &c.MongoDB.Password)
+	setString("MONGODB_DATABASE", &c.MongoDB.Database)
+	setInt("MONGODB_MAX_POOL_SIZE", &c.MongoDB.MaxPoolSize)
+	setInt("MONGODB_MIN_POOL_SIZE", &c.MongoDB.MinPoolSize)
+	setBool("MONGODB_LAZY_CONNECT", &c.MongoDB.LazyConnect)
+	setString("ENCRYPTION_KEY_FILE", &c.Security.EncryptionKeyFile)
+	setString("ADMIN_KEY", &c.Security.AdminKey)
+	setBool("TRACING_ENABLED", &c.Tracing.Enabled)
+	setString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	setString("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	setBool("LIMITS_ENABLED", &c.Limits.Enabled)
+	setString("LIMITS_BACKEND", &c.Limits.Backend)
+	setBool("MIGRATIONS_AUTO_APPLY", &c.Migrations.AutoApply)
+
+	var timeoutSeconds int
+	setInt("MONGODB_TIMEOUT_SECONDS", &timeoutSeconds)
+	if timeoutSeconds > 0 {
+		c.MongoDB.Timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if _, err := zerolog.ParseLevel(c.Logging.Level); err != nil || c.Logging.Level == "" {
+		errs = append(errs, fmt.Errorf("logging.level %q is not a valid level", c.Logging.Level))
+	}
+	if c.Server.MaxRequestTimeout <= 0 {
+		errs = append(errs, errors.New("server.max_request_timeout must be positive"))
+	}
+	if c.Logging.Format != "json" && c.Logging.Format != "console" {
+		errs = append(errs, fmt.Errorf("logging.format must be json or console, got %q", c.Logging.Format))
+	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowed_origins must not be empty"))
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, errors.New("cors.allowed_origins must not contain * when cors.allow_credentials is set"))
+			}
+			continue
+		}
+		// The CORS middleware only accepts http and https origins besides *
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins contains invalid origin %q, origins must be * or http(s)://host", origin))
+		}
+	}
+	if c.MongoDB.URI == "" && c.MongoDB.Host == "" {
+		errs = append(errs, errors.New("mongodb.uri or mongodb.host must be set"))
+	}
+	if c.MongoDB.URI == "" && (c.MongoDB.Port < 1 || c.MongoDB.Port > 65535) {
+		errs = append(errs, fmt.Errorf("mongodb.port must be between 1 and 65535, got %d", c.MongoDB.Port))
+	}
+	if c.MongoDB.Database == "" {
+		errs = append(errs, errors.New("mongodb.database must be set"))
+	}
+	if c.MongoDB.Timeout <= 0 {
+		errs = append(errs, errors.New("mongodb.timeout must be positive"))
+	}
+	if c.MongoDB.ReadTimeout < 0 || c.MongoDB.WriteTimeout < 0 || c.MongoDB.AggregateTimeout < 0 {
+		errs = append(errs, errors.New("mongodb read, write and aggregate timeouts must not be negative"))
+	}
+	if c.MongoDB.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("mongodb.connect_timeout must be positive"))
+	}
+	if c.MongoDB.MaxPoolSize < 0 || c.MongoDB.MinPoolSize < 0 {
+		errs = append(errs, errors.New("mongodb.max_pool_size and mongodb.min_pool_size must not be negative"))
+	} else if c.MongoDB.MaxPoolSize > 0 && c.MongoDB.MinPoolSize > c.MongoDB.MaxPoolSize {
+		errs = append(errs, fmt.Errorf("mongodb.min_pool_size (%d) must not exceed mongodb.max_pool_size (%d)", c.MongoDB.MinPoolSize, c.MongoDB.MaxPoolSize))
+	}
+	if c.Health.PingTimeout <= 0 {
+		errs = append(errs, errors.New("health.ping_timeout must be positive"))
+	}
+	if c.Health.CacheTTL < 0 {
+		errs = append(errs, errors.New("health.cache_ttl must not be negative"))
+	}
+	if c.Migrations.AutoApply && c.Migrations.Timeout <= 0 {
+		errs = append(errs, errors.New("migrations.timeout must be positive"))
+	}
+	if c.Retention.DeletedAfter <= 0 {
+		errs = append(errs, errors.New("retention.deleted_after must be positive"))
+	}
+	if c.Retention.PurgeInterval < 0 {
+		errs = append(errs, errors.New("retention.purge_interval must not be negative"))
+	}
+	if c.Snapshots.Interval < 0 {
+		errs = append(errs, errors.New("snapshots.interval must not be negative"))
+	}
+	if c.Snapshots.Retention <= 0 {
+		errs = append(errs, errors.New("snapshots.retention must be positive"))
+	}
+	if c.Snapshots.Retention > c.Retention.DeletedAfter {
+		errs = append(errs, errors.New("snapshots.retention must not exceed retention.deleted_after, purged spaces cannot be reconstructed"))
+	}
+	if c.Metrics.Enabled && c.Metrics.RefreshInterval <= 0 {
+		errs = append(errs, errors.New("metrics.refresh_interval must be positive"))
+	}
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp", "stdout":
+		case "file":
+			if c.Tracing.FilePath == "" {
+				errs = append(errs, errors.New("tracing.file_path must be set for the file exporter"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("tracing.exporter must be otlp, stdout or file, got %q", c.Tracing.Exporter))
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio))
+		}
+	}
+	if c.Limits.Enabled {
+		if c.Limits.Backend != "memory" && c.Limits.Backend != "mongodb" {
+			errs = append(errs, fmt.Errorf("limits.backend must be memory or mongodb, got %q", c.Limits.Backend))
+		}
+		errs = append(errs, c.Limits.Default.validate("limits.default")...)
+		for i, rule := range c.Limits.Routes {
+			field := fmt.Sprintf("limits.routes[%d]", i)
+			if rule.Name == "" || rule.PathPrefix == "" {
+				errs = append(errs, fmt.Errorf("%s must have a name and a path_prefix", field))
+			}
+			errs = append(errs, rule.validate(field)...)
+		}
+	}
+	if c.Reload.WatchInterval < 0 {
+		errs = append(errs, errors.New("reload.watch_interval must not be negative"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate checks that the rule limits are usable
+func (r LimitRule) validate(field string) []error {
+	var errs []error
+	if r.RequestsPerSecond <= 0 {
+		errs = append(errs, fmt.Errorf("%s.requests_per_second must be positive", field))
+	}
+	if r.Burst < 1 {
+		errs = append(errs, fmt.Errorf("%s.burst must be at least 1", field))
+	}
+	if r.MaxBodyBytes < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_body_bytes must not be negative", field))
+	}
+	return errs
+}
+
+// Address returns the listen address of the HTTP server
+func (s ServerConfig) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// ConnectionURI returns the MongoDB URI, built from host, port and credentials unless set explicitly
+func (m MongoConfig) ConnectionURI() string {
+	if m.URI != "" {
+		return m.URI
+	}
+	u := url.URL{Scheme: "mongodb", Host: fmt.Sprintf("%s:%d", m.Host, m.Port)}
+	if m.Username != "" && m.Password != "" {
+		u.User = url.UserPassword(m.Username, m.Password)
+	}
+	return u.String()
+}
+
+// Path returns the file the configuration was read from, empty when it was built from defaults and environment only
+func (c *Config) Path() string {
+	return c.path
+}
+
+// IsProduction reports whether the service runs in the production environment
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// splitList splits a comma separated environment value into its trimmed, non-empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers the configuration admin routes on the given group
+func (r *Reloader) RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/status", r.GetReloadStatus)
+}
+
+// GetReloadStatus reports the outcome of the most recent configuration reload
+// @Summary Get the configuration reload status
+// @Description Report the generation of the configuration in effect, when it was loaded and whether the most recent reload succeeded. Settings that require a restart are listed as rejected. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} ReloadStatus "Configuration reload status"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Router /api/admin/config/status [get]
+func (r *Reloader) GetReloadStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, r.Status())
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Reload triggers reported in ReloadStatus
+const (
+	TriggerFile   = "file"
+	TriggerSignal = "signal"
+)
+
+// ReloadStatus describes the outcome of the most recent configuration reload
+type ReloadStatus struct {
+	Path        string     `json:"path,omitempty"`
+	Generation  int        `json:"generation"`
+	LoadedAt    time.Time  `json:"loaded_at"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	LastTrigger string     `json:"last_trigger,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	// Rejected lists the settings of the last successful reload that require a restart and were not applied
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+// Reloader keeps the current configuration and replaces it when the configuration
+// file changes or the process receives SIGHUP. Only runtime settings are reloaded;
+// changes to settings that are bound at startup are logged and ignored.
+type Reloader struct {
+	current   atomic.Pointer[Config]
+	logger    zerolog.Logger
+	mu        sync.Mutex
+	listeners []func(*Config)
+	checks    []func(*Config) error
+	status    ReloadStatus
+	checksum  []byte
+}
+
+// NewReloader creates a reloader starting from the given configuration
+func NewReloader(cfg *Config, logger zerolog.Logger) *Reloader {
+	r := &Reloader{
+		logger: logger.With().Str("component", "config").Logger(),
+		status: ReloadStatus{Path: cfg.Path(), Generation: 1, LoadedAt: time.Now()},
+	}
+	r.current.Store(cfg)
+	if cfg.Path() != "" {
+		r.checksum, _ = fileChecksum(cfg.Path())
+	}
+	return r
+}
+
+// Current returns the configuration currently in effect
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registers a function called with the new configuration after each successful reload
+func (r *Reloader) OnReload(listener func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// AddCheck registers a function that validates a new configuration before it is
+// applied, e.g. by building what a listener will build from it. A reload is
+// rejected when a check fails, so listeners only see configurations they accept.
+func (r *Reloader) AddCheck(check func(*Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+}
+
+// Status returns the outcome of the most recent reload
+func (r *Reloader) Status() ReloadStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	status.Rejected = append([]string(nil), r.status.Rejected...)
+	return status
+}
+
+// Reload reads the configuration file again and applies its runtime settings.
+// The current configuration stays in effect when the file is invalid.
+func (r *Reloader) Reload(trigger string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.current.Load()
+	now := time.Now()
+	r.status.LastAttempt = &now
+	r.status.LastTrigger = trigger
+
+	if previous.Path() == "" {
+		err := errors.New("no configuration file to reload, the service was started without one")
+		r.status.LastError = err.Error()
+		r.logger.Warn().Str("trigger", trigger).Msg(err.Error())
+		return err
+	}
+
+	// Remember the attempted content so that an invalid file is not retried on every poll
+	r.checksum, _ = fileChecksum(previous.Path())
+
+	next := Default()
+	err := next.readFile(previous.Path())
+	if err == nil {
+		err = next.applyEnv()
+	}
+	if err == nil {
+		err = next.Validate()
+	}
+	for _, check := range r.checks {
+		if err != nil {
+			break
+		}
+		err = check(next)
+	}
+	if err != nil {
+		r.status.LastError = err.Error()
+		r.logger.Error().Err(err).Str("trigger", trigger).Msg("Configuration reload failed, keeping the current configuration")
+		return err
+	}
+
+	rejected := next.keepStartupSettings(previous)
+	for _, setting := range rejected {
+		r.logger.Warn().Str("setting", setting).Msg("Configuration change requires a restart and was not applied")
+	}
+
+	r.current.Store(next)
+	r.status.Generation++
+	r.status.LoadedAt = now
+	r.status.LastSuccess = &now
+	r.status.LastError = ""
+	r.status.Rejected = rejected
+
+	for _, listener := range r.listeners {
+		listener(next)
+	}
+
+	r.logger.Info().Str("trigger", trigger).Int("generation", r.status.Generation).Msg("Configuration reloaded")
+	return nil
+}
+
+// Watch reloads the configuration whenever the file content changes or SIGHUP is
+// received, until ctx is cancelled. The file is polled rather than watched for
+// events because Kubernetes replaces ConfigMap mounts through symlink swaps.
+func (r *Reloader) Watch(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	cfg := r.Current()
+	if interval := cfg.Reload.WatchInterval; interval > 0 && cfg.Path() != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			_ = r.Reload(TriggerSignal)
+		case <-tick:
+			if r.fileChanged() {
+				_ = r.Reload(TriggerFile)
+			}
+		}
+	}
+}
+
+// fileChanged reports whether the configuration file differs from the last loaded content
+func (r *Reloader) fileChanged() bool {
+	checksum, err := fileChecksum(r.Current().Path())
+	if err != nil {
+		// A ConfigMap update briefly removes the file, the next poll picks it up
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !bytes.Equal(checksum, r.checksum)
+}
+
+// keepStartupSettings restores the settings that are bound at startup from previous
+// and returns the names of those that were changed
+func (c *Config) keepStartupSettings(previous *Config) []string {
+	var rejected []string
+	keepSetting(&rejected, "environment", &c.Environment, previous.Environment)
+	keepSetting(&rejected, "server", &c.Server, previous.Server)
+	keepSetting(&rejected, "logging.format", &c.Logging.Format, previous.Logging.Format)
+	keepSetting(&rejected, "mongodb", &c.MongoDB, previous.MongoDB)
+	keepSetting(&rejected, "security", &c.Security, previous.Security)
+	keepSetting(&rejected, "reload", &c.Reload, previous.Reload)
+	keepSetting(&rejected, "health", &c.Health, previous.Health)
+	keepSetting(&rejected, "migrations", &c.Migrations, previous.Migrations)
+	keepSetting(&rejected, "retention", &c.Retention, previous.Retention)
+	keepSetting(&rejected, "snapshots", &c.Snapshots, previous.Snapshots)
+	keepSetting(&rejected, "metrics", &c.Metrics, previous.Metrics)
+	keepSetting(&rejected, "tracing", &c.Tracing, previous.Tracing)
+	keepSetting(&rejected, "limits.enabled", &c.Limits.Enabled, previous.Limits.Enabled)
+	keepSetting(&rejected, "limits.backend", &c.Limits.Backend, previous.Limits.Backend)
+	return rejected
+}
+
+// keepSetting resets next to current and records name in rejected when they differ
+func keepSetting[T any](rejected *[]string, name string, next *T, current T) {
+	if !reflect.DeepEqual(*next, current) {
+		*rejected = append(*rejected, name)
+		*next = current
+	}
+}
+
+// fileChecksum returns the SHA-256 checksum of the file at path
+func fileChecksum(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// loadTestConfig writes content to a configuration file and loads it
+func loadTestConfig(t *testing.T, content string) (*Config, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeTestConfig(t, path, content)
+	t.Setenv(envPrefix+"CONFIG", path)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg, path
+}
+
+func writeTestConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestValidateCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		valid   bool
+	}{
+		{"http origin", []string{"http://localhost:3000"}, true},
+		{"https origin", []string{"https://example.org"}, true},
+		{"wildcard", []string{"*"}, true},
+		{"ftp origin", []string{"ftp://example.org"}, false},
+		{"browser extension", []string{"chrome-extension://abc"}, false},
+		{"missing scheme", []string{"example.org"}, false},
+		{"missing host", []string{"https://"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.CORS.AllowedOrigins = tt.origins
+			cfg.CORS.AllowCredentials = false
+			err := cfg.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected the origins to be valid, got %v", err)
+			}
+			if !tt.valid && (err == nil || !strings.Contains(err.Error(), "cors.allowed_origins")) {
+				t.Errorf("expected a cors.allowed_origins error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReloadRejectsUnsupportedCORSOrigin(t *testing.T) {
+	cfg, path := loadTestConfig(t, "cors:\n  allowed_origins: [\"https://example.org\"]\n")
+	reloader := NewReloader(cfg, zerolog.Nop())
+	reloaded := false
+	reloader.OnReload(func(*Config) { reloaded = true })
+
+	writeTestConfig(t, path, "cors:\n  allowed_origins: [\"ftp://example.org\"]\n")
+	if err := reloader.Reload(TriggerSignal); err == nil {
+		t.Fatal("expected the reload to be rejected")
+	}
+
+	if reloaded {
+		t.Error("expected listeners not to be called for a rejected reload")
+	}
+	if origins := reloader.Current().CORS.AllowedOrigins; len(origins) != 1 || origins[0] != "https://example.org" {
+		t.Errorf("expected the current origins to stay in effect, got %v", origins)
+	}
+	status := reloader.Status()
+	if status.Generation != 1 || !strings.Contains(status.LastError, "ftp://example.org") {
+		t.Errorf("expected generation 1 with the rejected origin as last error, got %+v", status)
+	}
+}
+
+func TestReloadRejectedByCheck(t *testing.T) {
+	cfg, path := loadTestConfig(t, "cors:\n  allowed_origins: [\"https://example.org\"]\n")
+	reloader := NewReloader(cfg, zerolog.Nop())
+	errRejected := errors.New("rejected by check")
+	reloader.AddCheck(func(next *Config) error {
+		if next.Logging.Level == "debug" {
+			return errRejected
+		}
+		return nil
+	})
+	reloaded := 0
+	reloader.OnReload(func(*Config) { reloaded++ })
+
+	writeTestConfig(t, path, "logging:\n  level: debug\ncors:\n  allowed_origins: [\"https://example.com\"]\n")
+	if err := reloader.Reload(TriggerSignal); !errors.Is(err, errRejected) {
+		t.Fatalf("expected the check to reject the reload, got %v", err)
+	}
+	if reloaded != 0 || reloader.Current().CORS.AllowedOrigins[0] != "https://example.org" {
+		t.Error("expected the rejected configuration not to be applied")
+	}
+
+	writeTestConfig(t, path, "cors:\n  allowed_origins: [\"https://example.com\"]\n")
+	if err := reloader.Reload(TriggerSignal); err != nil {
+		t.Fatalf("expected the reload to succeed, got %v", err)
+	}
+	if reloaded != 1 || reloader.Current().CORS.AllowedOrigins[0] != "https://example.com" {
+		t.Errorf("expected the new origin to be applied, got %v", reloader.Current().CORS.AllowedOrigins)
+	}
+	if status := reloader.Status(); status.Generation != 2 || status.LastError != "" {
+		t.Errorf("expected generation 2 without error, got %+v", status)
+	}
+}
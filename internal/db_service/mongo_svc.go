@@ -2,92 +2,130 @@ package db_service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"sync"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/rosadsky/ros-project-backend/internal/config"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-// DbService provides database connection and operations
-type DbService struct {
-	Client   *mongo.Client
-	Database *mongo.Database
-	timeout  time.Duration
+// Options configures a DbService
+type Options struct {
+	// URI is the MongoDB connection string
+	URI string
+	// Database is the name of the database the service operates on
+	Database string
+	// ConnectTimeout bounds the initial connection check
+	ConnectTimeout time.Duration
+	// OperationTimeout bounds the contexts returned by CreateContext and is the
+	// default for read, write and aggregation timeouts that are not set
+	OperationTimeout time.Duration
+	// ReadTimeout, WriteTimeout and AggregateTimeout bound the contexts returned by
+	// ReadContext, WriteContext and AggregateContext
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	AggregateTimeout time.Duration
+	// MaxPoolSize and MinPoolSize bound the connection pool, a zero MaxPoolSize means unlimited
+	MaxPoolSize uint64
+	MinPoolSize uint64
+	// Monitors receive the started, succeeded and failed events of database commands
+	Monitors []*event.CommandMonitor
+	// Lazy skips the initial connection check. The driver connects on first use and
+	// re-establishes connections after the server becomes reachable again, so the
+	// service can start while MongoDB is still unavailable.
+	Lazy bool
 }
 
-var (
-	dbService *DbService
-	once      sync.Once
-)
-
-// NewDbService creates a new database service instance
-func NewDbService() *DbService {
-	once.Do(func() {
-		dbService = &DbService{
-			timeout: 10 * time.Second,
-		}
-		dbService.connect()
-	})
-	return dbService
+// OptionsFromConfig builds the options for the configured MongoDB connection
+func OptionsFromConfig(cfg config.MongoConfig) Options {
+	return Options{
+		URI:              cfg.ConnectionURI(),
+		Database:         cfg.Database,
+		ConnectTimeout:   cfg.ConnectTimeout,
+		OperationTimeout: cfg.Timeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		AggregateTimeout: cfg.AggregateTimeout,
+		MaxPoolSize:      uint64(cfg.MaxPoolSize),
+		MinPoolSize:      uint64(cfg.MinPoolSize),
+		Lazy:             cfg.LazyConnect,
+	}
 }
 
-// connect establishes connection to MongoDB
-func (db *DbService) connect() {
-	mongoURI := os.Getenv("AMBULANCE_API_MONGODB_URI")
-	if mongoURI == "" {
-		// Build MongoDB URI from individual environment variables
-		mongoHost := os.Getenv("AMBULANCE_API_MONGODB_HOST")
-		if mongoHost == "" {
-			mongoHost = "localhost"
-		}
-
-		mongoPort := os.Getenv("AMBULANCE_API_MONGODB_PORT")
-		if mongoPort == "" {
-			mongoPort = "27017"
-		}
-
-		mongoUser := os.Getenv("AMBULANCE_API_MONGODB_USERNAME")
-		mongoPassword := os.Getenv("AMBULANCE_API_MONGODB_PASSWORD")
+// DbService provides database connection and operations
+type DbService struct {
+	Client           *mongo.Client
+	Database         *mongo.Database
+	timeout          time.Duration
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	aggregateTimeout time.Duration
+}
 
-		// Log credential status for debugging (without exposing actual credentials)
-		if mongoUser != "" && mongoPassword != "" {
-			log.Printf("Using MongoDB authentication with username: %s", mongoUser)
-			mongoURI = fmt.Sprintf("mongodb://%s:%s@%s:%s", mongoUser, mongoPassword, mongoHost, mongoPort)
-		} else {
-			mongoURI = fmt.Sprintf("mongodb://%s:%s", mongoHost, mongoPort)
-		}
+// New connects to MongoDB and returns a database service for the configured database.
+// Unless opts.Lazy is set, the connection is verified with a ping bounded by ctx and
+// opts.ConnectTimeout, and an unreachable server is reported as an error. The caller
+// owns the service and must call Disconnect when done.
+func New(ctx context.Context, opts Options) (*DbService, error) {
+	if opts.URI == "" {
+		return nil, errors.New("mongodb URI must be set")
 	}
-
-	databaseName := os.Getenv("AMBULANCE_API_MONGODB_DATABASE")
-	if databaseName == "" {
-		databaseName = "hospital-spaces" // default database name
+	if opts.Database == "" {
+		return nil, errors.New("mongodb database must be set")
+	}
+	if opts.OperationTimeout <= 0 {
+		return nil, errors.New("mongodb operation timeout must be positive")
 	}
 
-	log.Printf("Connecting to MongoDB at %s", mongoURI)
-	log.Printf("Database: %s", databaseName)
+	clientOptions := options.Client().
+		ApplyURI(opts.URI).
+		SetMaxPoolSize(opts.MaxPoolSize).
+		SetMinPoolSize(opts.MinPoolSize).
+		SetMonitor(combineMonitors(opts.Monitors))
+	if opts.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(opts.ConnectTimeout).SetServerSelectionTimeout(opts.ConnectTimeout)
+	}
 
-	clientOptions := options.Client().ApplyURI(mongoURI)
-	client, err := mongo.Connect(context.Background(), clientOptions)
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
-	// Test the connection
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
-	defer cancel()
+	db := &DbService{
+		Client:           client,
+		Database:         client.Database(opts.Database),
+		timeout:          opts.OperationTimeout,
+		readTimeout:      orDefault(opts.ReadTimeout, opts.OperationTimeout),
+		writeTimeout:     orDefault(opts.WriteTimeout, opts.OperationTimeout),
+		aggregateTimeout: orDefault(opts.AggregateTimeout, opts.OperationTimeout),
+	}
 
-	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+	if !opts.Lazy {
+		pingCtx := ctx
+		if opts.ConnectTimeout > 0 {
+			var cancel context.CancelFunc
+			pingCtx, cancel = context.WithTimeout(ctx, opts.ConnectTimeout)
+			defer cancel()
+		}
+		if err := db.Ping(pingCtx); err != nil {
+			_ = client.Disconnect(context.Background())
+			return nil, err
+		}
 	}
 
-	db.Client = client
-	db.Database = client.Database(databaseName)
-	log.Printf("Successfully connected to MongoDB database: %s", databaseName)
+	return db, nil
+}
+
+// Ping checks that the database server is reachable
+func (db *DbService) Ping(ctx context.Context) error {
+	if err := db.Client.Ping(ctx, readpref.Primary()); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return nil
 }
 
 // GetCollection returns a MongoDB collection
@@ -105,78 +143,67 @@ func (db *DbService) Disconnect() error {
 	return nil
 }
 
-// CreateContext creates a context with timeout for database operations
+// CreateContext creates a context with timeout for database operations that are not
+// part of a request, e.g. startup tasks and background jobs. Request handlers use
+// ReadContext, WriteContext or AggregateContext instead.
 func (db *DbService) CreateContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), db.timeout)
 }
 
-// EnsureIndexes creates necessary indexes for collections
-func (db *DbService) EnsureIndexes() error {
-	ctx, cancel := db.CreateContext()
-	defer cancel()
-
-	// Create indexes for spaces collection
-	spacesCollection := db.GetCollection("spaces")
-	indexModels := []mongo.IndexModel{
-		{
-			Keys: bson.D{
-				{Key: "name", Value: 1},
-				{Key: "type", Value: 1},
-			},
-		},
-		{
-			Keys: bson.D{
-				{Key: "floor", Value: 1},
-			},
-		},
-		{
-			Keys: bson.D{
-				{Key: "status", Value: 1},
-			},
-		},
-		{
-			Keys: bson.D{
-				{Key: "space_id", Value: 1},
-			},
-			Options: options.Index().SetUnique(true),
-		},
-	}
+// ReadContext derives a context for queries from the request context, so that they
+// are cancelled when the client disconnects or the request deadline passes
+func (db *DbService) ReadContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, db.readTimeout)
+}
 
-	_, err := spacesCollection.Indexes().CreateMany(ctx, indexModels)
-	if err != nil {
-		// Log warning but don't fail the application
-		log.Printf("Warning: failed to create spaces indexes: %v", err)
-		return nil // Don't return error, just warn
+// WriteContext derives a context for inserts, updates and deletes from the request context
+func (db *DbService) WriteContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, db.writeTimeout)
+}
+
+// AggregateContext derives a context for aggregation pipelines from the request context
+func (db *DbService) AggregateContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, db.aggregateTimeout)
+}
+
+// combineMonitors returns a command monitor forwarding events to all given monitors
+func combineMonitors(monitors []*event.CommandMonitor) *event.CommandMonitor {
+	switch len(monitors) {
+	case 0:
+		return nil
+	case 1:
+		return monitors[0]
 	}
 
-	// Create indexes for ambulances collection
-	ambulancesCollection := db.GetCollection("ambulances")
-	ambulanceIndexModels := []mongo.IndexModel{
-		{
-			Keys: bson.D{
-				{Key: "name", Value: 1},
-			},
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Started != nil {
+					monitor.Started(ctx, e)
+				}
+			}
 		},
-		{
-			Keys: bson.D{
-				{Key: "status", Value: 1},
-			},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				if monitor.Succeeded != nil {
+					monitor.Succeeded(ctx, e)
+				}
+			}
 		},
-		{
-			Keys: bson.D{
-				{Key: "ambulance_id", Value: 1},
-			},
-			Options: options.Index().SetUnique(true),
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Failed != nil {
+					monitor.Failed(ctx, e)
+				}
+			}
 		},
 	}
+}
 
-	_, err = ambulancesCollection.Indexes().CreateMany(ctx, ambulanceIndexModels)
-	if err != nil {
-		// Log warning but don't fail the application
-		log.Printf("Warning: failed to create ambulances indexes: %v", err)
-		return nil // Don't return error, just warn
+// orDefault returns timeout, or fallback when timeout is not set
+func orDefault(timeout, fallback time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
 	}
-
-	log.Println("Database indexes created successfully")
-	return nil
+	return fallback
 }
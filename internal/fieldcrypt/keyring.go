@@ -0,0 +1,166 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks values encrypted by this package: enc:v1:<key id>:<base64(nonce|ciphertext)>
+const prefix = "enc:v1:"
+
+// KeyFile is the on-disk format of the encryption key file
+type KeyFile struct {
+	ActiveKeyID string     `json:"active_key_id"`
+	Keys        []KeyEntry `json:"keys"`
+}
+
+// KeyEntry is a single base64 encoded 256-bit AES key
+type KeyEntry struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// Keyring encrypts values with the active key and decrypts values with any known key.
+// A nil Keyring leaves values untouched, which keeps encryption optional for development.
+type Keyring struct {
+	activeID string
+	aeads    map[string]cipher.AEAD
+}
+
+// LoadKeyring reads a key file and builds a keyring from it
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file KeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+
+	return NewKeyring(file)
+}
+
+// NewKeyring builds a keyring from the keys of a key file
+func NewKeyring(file KeyFile) (*Keyring, error) {
+	keyring := &Keyring{activeID: file.ActiveKeyID, aeads: map[string]cipher.AEAD{}}
+	for _, entry := range file.Keys {
+		if entry.ID == "" || strings.Contains(entry.ID, ":") {
+			return nil, fmt.Errorf("invalid key id %q", entry.ID)
+		}
+		if _, ok := keyring.aeads[entry.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", entry.ID)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", entry.ID, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %d", entry.ID, len(key))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.aeads[entry.ID] = aead
+	}
+
+	if _, ok := keyring.aeads[file.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the key file", file.ActiveKeyID)
+	}
+
+	return keyring, nil
+}
+
+// GenerateKey returns a new random key entry with the given id
+func GenerateKey(id string) (KeyEntry, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return KeyEntry{}, err
+	}
+	return KeyEntry{ID: id, Key: base64.StdEncoding.EncodeToString(key)}, nil
+}
+
+// ActiveKeyID returns the id of the key used for encryption
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeID
+}
+
+// Encrypt encrypts a value with the active key. The key id is bound to the ciphertext
+// as additional data, so a value cannot be relabelled with another key id.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil {
+		return plaintext, nil
+	}
+
+	aead := k.aeads[k.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.activeID))
+	return prefix + k.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the encryption prefix
+// are returned unchanged, so data written before encryption was enabled stays readable.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if k == nil {
+		return "", errors.New("encrypted value found but no encryption key file is configured")
+	}
+
+	keyID, payload, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key %q", keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value: too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %w", keyID, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether a value is plaintext or encrypted with a key other than the active one
+func (k *Keyring) NeedsReencryption(value string) bool {
+	if k == nil {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.activeID+":")
+}
+
+// IsEncrypted reports whether a value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,216 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// newTestKeyring builds a keyring with a generated key for every id, encrypting with active
+func newTestKeyring(t *testing.T, active string, ids ...string) *Keyring {
+	t.Helper()
+	file := KeyFile{ActiveKeyID: active}
+	for _, id := range ids {
+		entry, err := GenerateKey(id)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		file.Keys = append(file.Keys, entry)
+	}
+	keyring, err := NewKeyring(file)
+	if err != nil {
+		t.Fatalf("failed to build keyring: %v", err)
+	}
+	return keyring
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	for _, plaintext := range []string{"", "Jane Doe", "Ľubomír Šťastný", strings.Repeat("x", 4096)} {
+		encrypted, err := keyring.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+		if !strings.HasPrefix(encrypted, "enc:v1:k1:") {
+			t.Errorf("expected the active key id in %q", encrypted)
+		}
+		if plaintext != "" && strings.Contains(encrypted, plaintext) {
+			t.Errorf("encrypted value contains the plaintext")
+		}
+
+		decrypted, err := keyring.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("failed to decrypt: %v", err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("expected %q, got %q", plaintext, decrypted)
+		}
+	}
+}
+
+func TestKeyringEncryptUsesFreshNonces(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	first, _ := keyring.Encrypt("Jane Doe")
+	second, _ := keyring.Encrypt("Jane Doe")
+	if first == second {
+		t.Error("expected different ciphertexts for the same plaintext")
+	}
+}
+
+func TestKeyringDecryptsWithRotatedKeys(t *testing.T) {
+	k1 := generatedKey(t, "k1")
+	old, err := NewKeyring(KeyFile{ActiveKeyID: "k1", Keys: []KeyEntry{k1}})
+	if err != nil {
+		t.Fatalf("failed to build keyring: %v", err)
+	}
+	encrypted, err := old.Encrypt("Jane Doe")
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	// The rotated keyring keeps the old key for decryption
+	rotated, err := NewKeyring(KeyFile{ActiveKeyID: "k2", Keys: []KeyEntry{k1, generatedKey(t, "k2")}})
+	if err != nil {
+		t.Fatalf("failed to build keyring: %v", err)
+	}
+
+	decrypted, err := rotated.Decrypt(encrypted)
+	if err != nil || decrypted != "Jane Doe" {
+		t.Errorf("expected the old value to decrypt, got %q, %v", decrypted, err)
+	}
+}
+
+func TestKeyringDecryptRejectsTampering(t *testing.T) {
+	shared := generatedKey(t, "k1")
+	// Both ids hold the same key, so only the key id bound as additional data tells them apart
+	keyring, err := NewKeyring(KeyFile{ActiveKeyID: "k1", Keys: []KeyEntry{shared, {ID: "k2", Key: shared.Key}}})
+	if err != nil {
+		t.Fatalf("failed to build keyring: %v", err)
+	}
+	encrypted, err := keyring.Encrypt("Jane Doe")
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	payload := strings.TrimPrefix(encrypted, "enc:v1:k1:")
+
+	tests := []struct {
+		name  string
+		value string
+		error string
+	}{
+		{"relabelled key id", "enc:v1:k2:" + payload, `failed to decrypt value with key "k2"`},
+		{"unknown key id", "enc:v1:k3:" + payload, `unknown encryption key "k3"`},
+		{"missing key id", "enc:v1:" + payload, "unknown encryption key"},
+		{"flipped ciphertext", "enc:v1:k1:" + flipLastByte(t, payload), `failed to decrypt value with key "k1"`},
+		{"invalid base64", "enc:v1:k1:not base64", "malformed encrypted value"},
+		{"too short", "enc:v1:k1:AAAA", "malformed encrypted value: too short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := keyring.Decrypt(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestKeyringNeedsReencryption(t *testing.T) {
+	rotated := newTestKeyring(t, "k2", "k1", "k2", "k20")
+	encryptedK2, _ := rotated.Encrypt("Jane Doe")
+	encryptedK1 := "enc:v1:k1:" + strings.TrimPrefix(encryptedK2, "enc:v1:k2:")
+
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		value   string
+		want    bool
+	}{
+		{"plaintext", rotated, "Jane Doe", true},
+		{"empty plaintext", rotated, "", true},
+		{"encrypted with an old key", rotated, encryptedK1, true},
+		{"encrypted with the active key", rotated, encryptedK2, false},
+		{"key id with the active id as prefix", rotated, "enc:v1:k20:AAAA", true},
+		{"nil keyring", nil, "Jane Doe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.keyring.NeedsReencryption(tt.value); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNilKeyring(t *testing.T) {
+	var keyring *Keyring
+
+	encrypted, err := keyring.Encrypt("Jane Doe")
+	if err != nil || encrypted != "Jane Doe" {
+		t.Errorf("expected the plaintext to pass through, got %q, %v", encrypted, err)
+	}
+	decrypted, err := keyring.Decrypt("Jane Doe")
+	if err != nil || decrypted != "Jane Doe" {
+		t.Errorf("expected the plaintext to pass through, got %q, %v", decrypted, err)
+	}
+	if keyring.ActiveKeyID() != "" {
+		t.Errorf("expected no active key id, got %q", keyring.ActiveKeyID())
+	}
+	if _, err := keyring.Decrypt("enc:v1:k1:AAAA"); err == nil {
+		t.Error("expected an error for an encrypted value without a keyring")
+	}
+}
+
+func TestKeyringPassesPlaintextThrough(t *testing.T) {
+	keyring := newTestKeyring(t, "k1", "k1")
+
+	decrypted, err := keyring.Decrypt("Jane Doe")
+	if err != nil || decrypted != "Jane Doe" {
+		t.Errorf("expected values written before encryption to pass through, got %q, %v", decrypted, err)
+	}
+}
+
+func TestNewKeyringRejectsInvalidKeys(t *testing.T) {
+	valid := generatedKey(t, "k1")
+
+	tests := []struct {
+		name string
+		file KeyFile
+	}{
+		{"empty key id", KeyFile{ActiveKeyID: "", Keys: []KeyEntry{{ID: "", Key: valid.Key}}}},
+		{"key id with a colon", KeyFile{ActiveKeyID: "a:b", Keys: []KeyEntry{{ID: "a:b", Key: valid.Key}}}},
+		{"duplicate key id", KeyFile{ActiveKeyID: "k1", Keys: []KeyEntry{valid, valid}}},
+		{"invalid base64", KeyFile{ActiveKeyID: "k1", Keys: []KeyEntry{{ID: "k1", Key: "not base64"}}}},
+		{"short key", KeyFile{ActiveKeyID: "k1", Keys: []KeyEntry{{ID: "k1", Key: "AAAA"}}}},
+		{"unknown active key", KeyFile{ActiveKeyID: "k2", Keys: []KeyEntry{valid}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.file); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func generatedKey(t *testing.T, id string) KeyEntry {
+	t.Helper()
+	entry, err := GenerateKey(id)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return entry
+}
+
+// flipLastByte changes the last byte of a base64 payload, which lies in the authentication tag
+func flipLastByte(t *testing.T, payload string) string {
+	t.Helper()
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	sealed[len(sealed)-1] ^= 0xff
+	return base64.StdEncoding.EncodeToString(sealed)
+}
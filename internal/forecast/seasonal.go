@@ -0,0 +1,68 @@
+package forecast
+
+import (
+	"errors"
+	"math"
+)
+
+// Z95 is the standard normal quantile of a two-sided 95% prediction interval
+const Z95 = 1.96
+
+// ErrInsufficientHistory is returned when the history does not cover two seasons
+var ErrInsufficientHistory = errors.New("the history must cover at least two seasons")
+
+// Prediction is the forecast of one step of a series with its prediction interval
+type Prediction struct {
+	Value float64
+	Lower float64
+	Upper float64
+	// Samples is the number of observations the prediction is the average of
+	Samples int
+}
+
+// SeasonalAverage forecasts the horizon steps that follow history, which holds
+// consecutive observations of a series repeating every period steps, e.g. 168 for
+// hours of a week. A step is predicted as the mean of the observations at the same
+// position of earlier seasons, and its interval is mean ± z·s·√(1+1/n), where s is
+// their standard deviation and n their number.
+func SeasonalAverage(history []float64, period, horizon int, z float64) ([]Prediction, error) {
+	if period < 1 || len(history) < 2*period {
+		return nil, ErrInsufficientHistory
+	}
+
+	// Welford's running mean and variance per position of the season
+	count := make([]int, period)
+	mean := make([]float64, period)
+	m2 := make([]float64, period)
+	for i, value := range history {
+		slot := i % period
+		count[slot]++
+		delta := value - mean[slot]
+		mean[slot] += delta / float64(count[slot])
+		m2[slot] += delta * (value - mean[slot])
+	}
+
+	predictions := make([]Prediction, horizon)
+	for step := range predictions {
+		slot := (len(history) + step) % period
+		n := float64(count[slot])
+		spread := z * math.Sqrt(m2[slot]/(n-1)) * math.Sqrt(1+1/n)
+		predictions[step] = Prediction{
+			Value:   mean[slot],
+			Lower:   mean[slot] - spread,
+			Upper:   mean[slot] + spread,
+			Samples: count[slot],
+		}
+	}
+	return predictions, nil
+}
+
+// Clamp limits a prediction and its interval to [lower, upper], e.g. to the
+// number of beds that exist
+func (p Prediction) Clamp(lower, upper float64) Prediction {
+	clamp := func(value float64) float64 { return math.Min(math.Max(value, lower), upper) }
+	p.Value = clamp(p.Value)
+	p.Lower = clamp(p.Lower)
+	p.Upper = clamp(p.Upper)
+	return p
+}
@@ -0,0 +1,125 @@
+package forecast
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSeasonalAverageInsufficientHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		period  int
+	}{
+		{"empty history", nil, 24},
+		{"less than two seasons", []float64{1, 2, 3, 4, 5}, 3},
+		{"zero period", []float64{1, 2, 3}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SeasonalAverage(tt.history, tt.period, 1, Z95)
+			if !errors.Is(err, ErrInsufficientHistory) {
+				t.Fatalf("expected ErrInsufficientHistory, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSeasonalAverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		period  int
+		horizon int
+		z       float64
+		want    []Prediction
+	}{
+		{
+			// Positions 0, 1 and 2 hold {1, 3, 5}, {2, 4} and {3, 5}; the horizon
+			// continues at position 1 because the history ends after position 0
+			name:    "slots continue after the history",
+			history: []float64{1, 2, 3, 3, 4, 5, 5},
+			period:  3,
+			horizon: 4,
+			z:       1,
+			want: []Prediction{
+				// s² = ((2-3)² + (4-3)²) / (2-1) = 2, spread = √2·√(1+1/2) = √3
+				{Value: 3, Lower: 3 - math.Sqrt(3), Upper: 3 + math.Sqrt(3), Samples: 2},
+				{Value: 4, Lower: 4 - math.Sqrt(3), Upper: 4 + math.Sqrt(3), Samples: 2},
+				// s² = (4 + 0 + 4) / (3-1) = 4, spread = 2·√(1+1/3)
+				{Value: 3, Lower: 3 - 2*math.Sqrt(4.0/3), Upper: 3 + 2*math.Sqrt(4.0/3), Samples: 3},
+				{Value: 3, Lower: 3 - math.Sqrt(3), Upper: 3 + math.Sqrt(3), Samples: 2},
+			},
+		},
+		{
+			name:    "interval scales with z",
+			history: []float64{0, 2},
+			period:  1,
+			horizon: 1,
+			z:       Z95,
+			// mean 1, s = √2, spread = 1.96·√2·√(1+1/2)
+			want: []Prediction{{Value: 1, Lower: 1 - Z95*math.Sqrt(3), Upper: 1 + Z95*math.Sqrt(3), Samples: 2}},
+		},
+		{
+			name:    "constant seasons have no spread",
+			history: []float64{4, 7, 4, 7},
+			period:  2,
+			horizon: 2,
+			z:       Z95,
+			want: []Prediction{
+				{Value: 4, Lower: 4, Upper: 4, Samples: 2},
+				{Value: 7, Lower: 7, Upper: 7, Samples: 2},
+			},
+		},
+		{
+			name:    "zero horizon",
+			history: []float64{1, 2},
+			period:  1,
+			horizon: 0,
+			z:       Z95,
+			want:    []Prediction{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SeasonalAverage(tt.history, tt.period, tt.horizon, tt.z)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d predictions, got %d", len(tt.want), len(got))
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if !approxEqual(p.Value, want.Value) || !approxEqual(p.Lower, want.Lower) ||
+					!approxEqual(p.Upper, want.Upper) || p.Samples != want.Samples {
+					t.Errorf("step %d: expected %+v, got %+v", i, want, p)
+				}
+			}
+		})
+	}
+}
+
+func TestPredictionClamp(t *testing.T) {
+	tests := []struct {
+		name       string
+		prediction Prediction
+		want       Prediction
+	}{
+		{"within bounds", Prediction{Value: 5, Lower: 2, Upper: 8}, Prediction{Value: 5, Lower: 2, Upper: 8}},
+		{"below zero", Prediction{Value: 1, Lower: -3, Upper: 4}, Prediction{Value: 1, Lower: 0, Upper: 4}},
+		{"above capacity", Prediction{Value: 11, Lower: 9, Upper: 13}, Prediction{Value: 10, Lower: 9, Upper: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prediction.Clamp(0, 10); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+)
+
+// Check and readiness states
+const (
+	StatusUp         = "up"
+	StatusDown       = "down"
+	StatusOK         = "ok"
+	StatusPending    = "pending"
+	StatusMissing    = "missing"
+	StatusUnknown    = "unknown"
+	StatusNotTracked = "not_tracked"
+	StatusReady      = "ready"
+	StatusNotReady   = "not_ready"
+	StatusAlive      = "alive"
+)
+
+// PendingMigrationsFunc lists the migrations that have not been applied yet
+type PendingMigrationsFunc func(ctx context.Context) ([]string, error)
+
+// MissingIndexesFunc lists the required indexes the database lacks
+type MissingIndexesFunc func(ctx context.Context) ([]string, error)
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status    string   `json:"status"`
+	LatencyMs *int64   `json:"latency_ms,omitempty"`
+	Pending   []string `json:"pending,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Readiness is the outcome of the readiness checks
+type Readiness struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether the service can take traffic
+func (r Readiness) Ready() bool {
+	return r.Status == StatusReady
+}
+
+// Liveness is the outcome of the liveness check
+type Liveness struct {
+	Status string `json:"status"`
+}
+
+// Checker runs the readiness checks and caches their result for a short time, so
+// that frequent probes from several kubelets do not turn into database load
+type Checker struct {
+	ping        func(ctx context.Context) error
+	pingTimeout time.Duration
+	cacheTTL    time.Duration
+
+	mu         sync.Mutex
+	migrations PendingMigrationsFunc
+	indexes    MissingIndexesFunc
+	cached     *Readiness
+	expiresAt  time.Time
+}
+
+// NewChecker creates a readiness checker for the given database
+func NewChecker(dbService *db_service.DbService, pingTimeout, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		ping:        dbService.Ping,
+		pingTimeout: pingTimeout,
+		cacheTTL:    cacheTTL,
+	}
+}
+
+// SetMigrations registers the source of pending migrations. Readiness fails
+// while migrations are pending.
+func (h *Checker) SetMigrations(pending PendingMigrationsFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.migrations = pending
+	h.cached = nil
+}
+
+// SetIndexes registers the source of missing indexes. Readiness fails while
+// required indexes are missing, the IDs they keep unique could be duplicated.
+func (h *Checker) SetIndexes(missing MissingIndexesFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.indexes = missing
+	h.cached = nil
+}
+
+// Readiness returns the cached readiness or runs the checks when the cache expired.
+// Concurrent callers wait for a single run of the checks.
+func (h *Checker) Readiness(ctx context.Context) Readiness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.cached != nil && now.Before(h.expiresAt) {
+		return *h.cached
+	}
+
+	// The result is shared with other probes, so it must not depend on this caller going away
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.pingTimeout)
+	defer cancel()
+
+	readiness := Readiness{
+		Status:    StatusReady,
+		CheckedAt: now,
+		Checks: map[string]CheckResult{
+			"mongodb":    h.checkMongo(ctx),
+			"migrations": h.checkMigrations(ctx),
+			"indexes":    h.checkIndexes(ctx),
+		},
+	}
+	if readiness.Checks["mongodb"].Status != StatusUp || readiness.Checks["migrations"].Status == StatusPending ||
+		readiness.Checks["indexes"].Status == StatusMissing {
+		readiness.Status = StatusNotReady
+	}
+
+	h.cached = &readiness
+	h.expiresAt = now.Add(h.cacheTTL)
+	return readiness
+}
+
+// checkMongo pings the database
+func (h *Checker) checkMongo(ctx context.Context) CheckResult {
+	start := time.Now()
+	err := h.ping(ctx)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return CheckResult{Status: StatusDown, LatencyMs: &latency, Error: err.Error()}
+	}
+	return CheckResult{Status: StatusUp, LatencyMs: &latency}
+}
+
+// checkMigrations reports the migrations that still have to be applied
+func (h *Checker) checkMigrations(ctx context.Context) CheckResult {
+	if h.migrations == nil {
+		return CheckResult{Status: StatusNotTracked}
+	}
+	pending, err := h.migrations(ctx)
+	if err != nil {
+		return CheckResult{Status: StatusUnknown, Error: err.Error()}
+	}
+	if len(pending) > 0 {
+		return CheckResult{Status: StatusPending, Pending: pending}
+	}
+	return CheckResult{Status: StatusOK}
+}
+
+// checkIndexes reports the required indexes that are missing
+func (h *Checker) checkIndexes(ctx context.Context) CheckResult {
+	if h.indexes == nil {
+		return CheckResult{Status: StatusNotTracked}
+	}
+	missing, err := h.indexes(ctx)
+	if err != nil {
+		return CheckResult{Status: StatusUnknown, Error: err.Error()}
+	}
+	if len(missing) > 0 {
+		return CheckResult{Status: StatusMissing, Missing: missing}
+	}
+	return CheckResult{Status: StatusOK}
+}
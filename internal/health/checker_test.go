@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestChecker creates a checker whose ping returns pingErr, without a database
+func newTestChecker(pingErr error) *Checker {
+	return &Checker{
+		ping:        func(context.Context) error { return pingErr },
+		pingTimeout: time.Second,
+	}
+}
+
+func TestReadinessIndexes(t *testing.T) {
+	tests := []struct {
+		name       string
+		indexes    MissingIndexesFunc
+		wantCheck  string
+		wantStatus string
+		missing    []string
+	}{
+		{
+			name:       "not tracked",
+			wantCheck:  StatusNotTracked,
+			wantStatus: StatusReady,
+		},
+		{
+			name:       "all indexes exist",
+			indexes:    func(context.Context) ([]string, error) { return nil, nil },
+			wantCheck:  StatusOK,
+			wantStatus: StatusReady,
+		},
+		{
+			name: "partial index missing",
+			indexes: func(context.Context) ([]string, error) {
+				return []string{"spaces.space_id (partial)", "ambulances.ambulance_id (partial)"}, nil
+			},
+			wantCheck:  StatusMissing,
+			wantStatus: StatusNotReady,
+			missing:    []string{"spaces.space_id (partial)", "ambulances.ambulance_id (partial)"},
+		},
+		{
+			name:       "indexes cannot be listed",
+			indexes:    func(context.Context) ([]string, error) { return nil, errors.New("listIndexes failed") },
+			wantCheck:  StatusUnknown,
+			wantStatus: StatusReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newTestChecker(nil)
+			if tt.indexes != nil {
+				checker.SetIndexes(tt.indexes)
+			}
+
+			readiness := checker.Readiness(context.Background())
+			check := readiness.Checks["indexes"]
+			if check.Status != tt.wantCheck {
+				t.Errorf("expected indexes check %q, got %q", tt.wantCheck, check.Status)
+			}
+			if readiness.Status != tt.wantStatus {
+				t.Errorf("expected readiness %q, got %q", tt.wantStatus, readiness.Status)
+			}
+			if !slices.Equal(check.Missing, tt.missing) {
+				t.Errorf("expected missing %v, got %v", tt.missing, check.Missing)
+			}
+		})
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	none := func(context.Context) ([]string, error) { return nil, nil }
+
+	tests := []struct {
+		name       string
+		pingErr    error
+		migrations PendingMigrationsFunc
+		want       string
+	}{
+		{"all checks pass", nil, none, StatusReady},
+		{"database down", errors.New("connection refused"), none, StatusNotReady},
+		{"migrations pending", nil, func(context.Context) ([]string, error) { return []string{"0007_space_snapshot_entries"}, nil }, StatusNotReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newTestChecker(tt.pingErr)
+			checker.SetMigrations(tt.migrations)
+			checker.SetIndexes(none)
+
+			if readiness := checker.Readiness(context.Background()); readiness.Status != tt.want {
+				t.Errorf("expected %q, got %q with checks %+v", tt.want, readiness.Status, readiness.Checks)
+			}
+		})
+	}
+}
+
+func TestReadinessIsCached(t *testing.T) {
+	checker := newTestChecker(nil)
+	checker.cacheTTL = time.Minute
+	calls := 0
+	checker.SetIndexes(func(context.Context) ([]string, error) {
+		calls++
+		return nil, nil
+	})
+
+	checker.Readiness(context.Background())
+	checker.Readiness(context.Background())
+	if calls != 1 {
+		t.Errorf("expected the checks to run once within the cache TTL, ran %d times", calls)
+	}
+
+	// Registering a check drops the cached result
+	checker.SetIndexes(func(context.Context) ([]string, error) { return []string{"spaces.space_id (partial)"}, nil })
+	if readiness := checker.Readiness(context.Background()); readiness.Status != StatusNotReady {
+		t.Errorf("expected the new check to apply, got %q", readiness.Status)
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers the liveness and readiness probes
+func (h *Checker) RegisterRoutes(engine *gin.Engine) {
+	probes := engine.Group("/healthz")
+	{
+		probes.GET("/live", h.GetLiveness)
+		probes.GET("/ready", h.GetReadiness)
+	}
+}
+
+// GetLiveness reports that the process is running
+// @Summary Liveness probe
+// @Description Report that the API process is running and serving requests. Does not check dependencies, a failing database must not restart the pod.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Liveness "Service is alive"
+// @Router /healthz/live [get]
+func (h *Checker) GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Liveness{Status: StatusAlive})
+}
+
+// GetReadiness reports whether the service can take traffic
+// @Summary Readiness probe
+// @Description Check that MongoDB answers a ping, that no migrations are pending and that the unique indexes the migrations create exist. The result is cached for a short time.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} Readiness "Service is ready"
+// @Failure 503 {object} Readiness "Service is not ready"
+// @Router /healthz/ready [get]
+func (h *Checker) GetReadiness(c *gin.Context) {
+	readiness := h.Readiness(c.Request.Context())
+	if !readiness.Ready() {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
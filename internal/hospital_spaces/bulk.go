@@ -0,0 +1,328 @@
+package hospital_spaces
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Formats of bulk imports and exports
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	// importBatchSize is the number of documents inserted with one command
+	importBatchSize = 500
+	// maxNDJSONLine bounds a single line of an NDJSON import
+	maxNDJSONLine = 1 << 20
+)
+
+var (
+	// errUnsupportedFormat is returned for formats other than csv and ndjson
+	errUnsupportedFormat = errors.New("format must be csv or ndjson")
+	// errInvalidFile is returned for an import file that cannot be read as a whole
+	errInvalidFile = errors.New("invalid import file")
+)
+
+// ImportError describes a row that could not be imported
+type ImportError struct {
+	Line    int                  `json:"line"`
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Fields  []problem.FieldError `json:"fields,omitempty"`
+}
+
+// ImportResult summarizes an import. Rows are imported independently, so the
+// valid rows of a file are imported even when other rows fail.
+type ImportResult struct {
+	DryRun   bool          `json:"dry_run"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []ImportError `json:"errors,omitempty"`
+}
+
+// reject records a row that was not imported
+func (r *ImportResult) reject(line int, rowErr *problem.Error) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportError{Line: line, Code: rowErr.Code, Message: rowErr.Message, Fields: rowErr.Fields})
+}
+
+// csvField reads a column of a CSV record by its header name
+type csvField func(name string) string
+
+// IsInvalidImport reports whether an import failed because of the format or the
+// content of the file rather than because of the service
+func IsInvalidImport(err error) bool {
+	return errors.Is(err, errInvalidFile) || errors.Is(err, errUnsupportedFormat)
+}
+
+// readImportRows decodes the rows of a CSV file with a header row or of an NDJSON
+// file, validates them with the binding rules of T and calls fn for each row
+// with its line number and either the request or the problem with the row.
+// The returned error reports an unreadable file or an error returned by fn.
+func readImportRows[T any](r io.Reader, format string, required []string, fromCSV func(csvField) (T, *problem.Error), fn func(line int, request T, rowErr *problem.Error) error) error {
+	emit := func(line int, request T, rowErr *problem.Error) error {
+		if rowErr == nil {
+			if err := binding.Validator.ValidateStruct(&request); err != nil {
+				rowErr = invalidRow(err)
+			}
+		}
+		return fn(line, request, rowErr)
+	}
+
+	switch format {
+	case FormatCSV:
+		return readCSVRows(r, required, fromCSV, emit)
+	case FormatNDJSON:
+		return readNDJSONRows(r, emit)
+	default:
+		return errUnsupportedFormat
+	}
+}
+
+// readCSVRows decodes the rows of a CSV file with a header row
+func readCSVRows[T any](r io.Reader, required []string, fromCSV func(csvField) (T, *problem.Error), fn func(line int, request T, rowErr *problem.Error) error) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("%w: the CSV header row is missing", errInvalidFile)
+		}
+		return fmt.Errorf("%w: failed to read the CSV header: %w", errInvalidFile, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: the CSV header is missing the %s column", errInvalidFile, name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var zero T
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			err = fn(parseErr.StartLine, zero, problem.Validation("malformed_row", parseErr.Err.Error()))
+		case err != nil:
+			return fmt.Errorf("failed to read CSV: %w", err)
+		default:
+			line, _ := reader.FieldPos(0)
+			field := func(name string) string {
+				if i, ok := columns[name]; ok && i < len(record) {
+					return strings.TrimSpace(record[i])
+				}
+				return ""
+			}
+			request, rowErr := fromCSV(field)
+			err = fn(line, request, rowErr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSONRows decodes a JSON object per line, blank lines are skipped
+func readNDJSONRows[T any](r io.Reader, fn func(line int, request T, rowErr *problem.Error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var request T
+		var rowErr *problem.Error
+		if err := json.Unmarshal(data, &request); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				rowErr = problem.Validation("malformed_row", "The row is not valid JSON")
+			} else {
+				rowErr = invalidRow(err)
+			}
+		}
+		if err := fn(line, request, rowErr); err != nil {
+			return err
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return fmt.Errorf("%w: line %d exceeds %d bytes", errInvalidFile, line+1, maxNDJSONLine)
+	}
+	return scanner.Err()
+}
+
+// invalidRow reports the invalid fields of a row
+func invalidRow(err error) *problem.Error {
+	rowErr := problem.InvalidBody(err)
+	if rowErr.Code == "validation_failed" {
+		rowErr.Message = "The row contains invalid fields"
+	}
+	return rowErr
+}
+
+// csvInt parses an integer column, an empty column is zero and left to the validation rules
+func csvInt(field csvField, name string, fields *[]problem.FieldError) int {
+	value := field(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		*fields = append(*fields, problem.FieldError{Field: name, Message: "must be of type int"})
+	}
+	return n
+}
+
+// csvOptionalInt parses an integer column, an empty column is nil
+func csvOptionalInt(field csvField, name string, fields *[]problem.FieldError) *int {
+	if field(name) == "" {
+		return nil
+	}
+	n := csvInt(field, name, fields)
+	return &n
+}
+
+// csvOptional returns a pointer to a non-empty column or nil
+func csvOptional(field csvField, name string) *string {
+	if value := field(name); value != "" {
+		return &value
+	}
+	return nil
+}
+
+// batchInserter collects the documents of an import and inserts them in unordered
+// batches, so that a failing document does not stop the rest of its batch
+type batchInserter[T any] struct {
+	insert func(ctx context.Context, docs []T) error
+	dryRun bool
+	result *ImportResult
+	docs   []T
+	lines  []int
+}
+
+// newBatchInserter creates an inserter recording its outcome in result
+func newBatchInserter[T any](result *ImportResult, insert func(ctx context.Context, docs []T) error) *batchInserter[T] {
+	return &batchInserter[T]{insert: insert, dryRun: result.DryRun, result: result}
+}
+
+// add queues a document and inserts the batch once it is full
+func (b *batchInserter[T]) add(ctx context.Context, line int, doc T) error {
+	b.docs = append(b.docs, doc)
+	b.lines = append(b.lines, line)
+	if len(b.docs) >= importBatchSize {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+// flush inserts the queued documents. Documents rejected by the database, e.g.
+// for a duplicate key, are reported as failed rows; other errors abort the import.
+func (b *batchInserter[T]) flush(ctx context.Context) error {
+	if len(b.docs) == 0 {
+		return nil
+	}
+	defer func() {
+		b.docs = b.docs[:0]
+		b.lines = b.lines[:0]
+	}()
+
+	if b.dryRun {
+		b.result.Imported += len(b.docs)
+		return nil
+	}
+
+	err := b.insert(ctx, b.docs)
+	var bulkErr mongo.BulkWriteException
+	if err != nil && (!errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil) {
+		return err
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		b.result.reject(b.lines[writeErr.Index], problem.From(writeErr))
+	}
+	b.result.Imported += len(b.docs) - len(bulkErr.WriteErrors)
+	return nil
+}
+
+// finish inserts the remaining documents and orders the errors by line
+func (b *batchInserter[T]) finish(ctx context.Context) error {
+	if err := b.flush(ctx); err != nil {
+		return err
+	}
+	slices.SortStableFunc(b.result.Errors, func(a, b ImportError) int { return a.Line - b.Line })
+	return nil
+}
+
+// exportWriter writes the rows of an export as CSV with a header row or as NDJSON
+type exportWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+// newExportWriter creates a writer for the format, writing the CSV header right away
+func newExportWriter(w io.Writer, format string, header []string) (*exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		return &exportWriter{csv: writer}, writer.Write(header)
+	case FormatNDJSON:
+		return &exportWriter{json: json.NewEncoder(w)}, nil
+	default:
+		return nil, errUnsupportedFormat
+	}
+}
+
+// write writes a row, value is encoded for NDJSON and record for CSV
+func (e *exportWriter) write(value interface{}, record []string) error {
+	if e.json != nil {
+		return e.json.Encode(value)
+	}
+	return e.csv.Write(record)
+}
+
+// flush writes buffered CSV rows
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// optionalString returns the value of an optional field or an empty string
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,187 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errAmbulanceStatusChanged is returned when the status of an ambulance changed after it was read
+var errAmbulanceStatusChanged = problem.Conflict("ambulance_status_changed", "The ambulance status was changed concurrently")
+
+// UpdateAmbulanceStatus changes the status of an ambulance
+// @Summary Change the status of an ambulance
+// @Description Move an ambulance along available → dispatched → en_route → arrived → available, or between available and maintenance. Dispatching opens a run that records the time of every transition until the ambulance is available again; returning to available before arrival cancels the run.
+// @Tags Ambulances
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique ambulance ID (UUID format)" format(uuid)
+// @Param status body AmbulanceStatusRequest true "New status"
+// @Success 200 {object} Ambulance "Status changed successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid ambulance ID or status"
+// @Failure 404 {object} problem.Problem "Ambulance not found"
+// @Failure 409 {object} problem.Problem "The ambulance cannot change to the status from its current status"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/ambulances/{id}/status [put]
+func (s *SpaceServiceImpl) UpdateAmbulanceStatus(c *gin.Context) {
+	ambulanceIDStr := c.Param("id")
+	if _, err := uuid.Parse(ambulanceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_ambulance_id", "Invalid ambulance ID"))
+		return
+	}
+
+	var request AmbulanceStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	collection := s.dbService.GetCollection(collectionAmbulances)
+	var ambulance Ambulance
+	err := collection.FindOne(ctx, notDeleted(bson.M{"ambulance_id": ambulanceIDStr})).Decode(&ambulance)
+	if err == mongo.ErrNoDocuments {
+		problem.Write(c, problem.NotFound("ambulance_not_found", errAmbulanceNotFound.Error()))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find ambulance"))
+		return
+	}
+
+	if !ambulance.CanTransition(request.Status) {
+		problem.Write(c, problem.Conflict("invalid_status_transition",
+			"An ambulance that is "+ambulance.Status+" cannot change to "+request.Status))
+		return
+	}
+
+	if err := s.changeStatus(ctx, &ambulance, request.Status); err != nil {
+		if errors.Is(err, errAmbulanceStatusChanged) {
+			problem.Write(c, errAmbulanceStatusChanged)
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update ambulance status"))
+		return
+	}
+
+	c.JSON(http.StatusOK, ambulance)
+}
+
+// changeStatus stores the new status of an ambulance together with its run in a
+// transaction. Without transaction support the run is written first and restored
+// when the status cannot be stored, so that a failed change leaves neither.
+func (s *SpaceServiceImpl) changeStatus(ctx context.Context, ambulance *Ambulance, status string) error {
+	session, err := s.dbService.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// The callback is retried on transient errors, each attempt starts afresh
+		at := time.Now()
+		if _, err := s.recordRun(sc, ambulance, status, at); err != nil {
+			return nil, err
+		}
+		return nil, s.storeStatus(sc, ambulance, status, at)
+	})
+	if !isTransactionUnsupported(err) {
+		return err
+	}
+
+	at := time.Now()
+	undo, err := s.recordRun(ctx, ambulance, status, at)
+	if err != nil {
+		return err
+	}
+	if err := s.storeStatus(ctx, ambulance, status, at); err != nil {
+		if undoErr := undo(ctx); undoErr != nil {
+			return fmt.Errorf("%w, restoring the ambulance run failed: %v", err, undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// storeStatus changes the status of the ambulance if it still has the status it
+// was read with, and updates ambulance to the stored document
+func (s *SpaceServiceImpl) storeStatus(ctx context.Context, ambulance *Ambulance, status string, at time.Time) error {
+	var updated Ambulance
+	err := s.dbService.GetCollection(collectionAmbulances).FindOneAndUpdate(ctx,
+		notDeleted(bson.M{"ambulance_id": ambulance.AmbulanceID, "status": ambulance.Status}),
+		bson.M{"$set": bson.M{"status": status, "status_changed_at": at, "updated_at": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return errAmbulanceStatusChanged
+	}
+	if err != nil {
+		return err
+	}
+	*ambulance = updated
+	return nil
+}
+
+// recordRun records the change of an ambulance to status in its open run and
+// returns a function that restores the run. Dispatching opens a new run, closing
+// a run left open by an earlier failure.
+func (s *SpaceServiceImpl) recordRun(ctx context.Context, ambulance *Ambulance, status string, at time.Time) (func(context.Context) error, error) {
+	runs := s.dbService.GetCollection(collectionAmbulanceRuns)
+	open := bson.M{"ambulance_id": ambulance.AmbulanceID, "available_at": nil}
+	noop := func(context.Context) error { return nil }
+
+	var set bson.M
+	switch status {
+	case AmbulanceStatusDispatched:
+		if _, err := runs.UpdateMany(ctx, open, bson.M{"$set": bson.M{"available_at": at, "cancelled": true}}); err != nil {
+			return nil, err
+		}
+		result, err := runs.InsertOne(ctx, &AmbulanceRun{
+			AmbulanceID:   ambulance.AmbulanceID,
+			AmbulanceType: ambulance.Type,
+			DispatchedAt:  at,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			_, err := runs.DeleteOne(ctx, bson.M{"_id": result.InsertedID})
+			return err
+		}, nil
+	case AmbulanceStatusEnRoute:
+		set = bson.M{"en_route_at": at}
+	case AmbulanceStatusArrived:
+		set = bson.M{"arrived_at": at}
+	case AmbulanceStatusAvailable:
+		if ambulance.Status == AmbulanceStatusMaintenance {
+			return noop, nil
+		}
+		set = bson.M{"available_at": at, "cancelled": ambulance.Status != AmbulanceStatusArrived}
+	default:
+		return noop, nil
+	}
+
+	var run AmbulanceRun
+	err := runs.FindOneAndUpdate(ctx, open, bson.M{"$set": set}).Decode(&run)
+	if err == mongo.ErrNoDocuments {
+		return noop, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		_, err := runs.ReplaceOne(ctx, bson.M{"_id": run.ID}, &run)
+		return err
+	}, nil
+}
@@ -0,0 +1,212 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errBatchAborted rolls back an atomic batch after one of its operations failed
+var errBatchAborted = errors.New("batch aborted")
+
+// errAssignedToRequired is returned for an assign operation without an assignee
+var errAssignedToRequired = problem.Validation("assigned_to_required", "Assign operations require assigned_to, or an assigned_type with assigned_id")
+
+// BatchSpaces applies assign, release, maintenance and delete operations to many spaces
+// @Summary Apply operations to many hospital spaces
+// @Description Apply up to 200 assign, release, maintenance and delete operations in request order, e.g. at a shift change. Assign operations take the fields of a space update and are validated like it, release frees the space, maintenance takes it out of service and delete marks it as deleted like the delete endpoint. In best_effort mode (the default) each operation is applied on its own. In atomic mode the operations run in a MongoDB transaction, so the first failing operation rolls back the whole batch and the other operations are reported with code batch_aborted; this mode requires MongoDB to run as a replica set. Every operation gets a result with the status the single-item endpoint would have returned.
+// @Tags Spaces
+// @Accept json
+// @Produce json
+// @Param batch body SpaceBatchRequest true "Operations and mode"
+// @Success 200 {object} SpaceBatchResponse "Result of every operation"
+// @Failure 400 {object} problem.Problem "Bad request - invalid operations, or atomic mode without transaction support"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces:batch [post]
+func (s *SpaceServiceImpl) BatchSpaces(c *gin.Context) {
+	var request SpaceBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+	if request.Mode == "" {
+		request.Mode = BatchModeBestEffort
+	}
+
+	var response *SpaceBatchResponse
+	if request.Mode == BatchModeAtomic {
+		var err error
+		response, err = s.runAtomicBatch(c.Request.Context(), request.Operations)
+		if err != nil {
+			if isTransactionUnsupported(err) {
+				problem.Write(c, problem.Validation("transactions_unsupported", "Atomic batches require MongoDB to run as a replica set, use mode best_effort instead"))
+				return
+			}
+			problem.Write(c, problem.Internal(err, "Failed to apply batch"))
+			return
+		}
+	} else {
+		// Best-effort batches never abort, failures are recorded per operation
+		response, _ = s.runBatch(c.Request.Context(), request.Operations, false)
+	}
+	response.Mode = request.Mode
+
+	// Patient placements are shown like in the single-item responses
+	var spaces []Space
+	var indexes []int
+	for i, result := range response.Results {
+		if result.Space != nil {
+			spaces = append(spaces, *result.Space)
+			indexes = append(indexes, i)
+		}
+	}
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+	if err := s.presentSpaces(ctx, c, spaces); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to resolve patient placement"))
+		return
+	}
+	for i, index := range indexes {
+		response.Results[index].Space = &spaces[i]
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// runAtomicBatch applies the operations in a transaction. A failing operation
+// aborts the transaction and is reported in the response together with the
+// operations it rolled back; other errors are returned.
+func (s *SpaceServiceImpl) runAtomicBatch(ctx context.Context, operations []SpaceBatchOperation) (*SpaceBatchResponse, error) {
+	session, err := s.dbService.Client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var response *SpaceBatchResponse
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// The callback is retried on transient errors, each attempt starts afresh
+		var err error
+		response, err = s.runBatch(sc, operations, true)
+		return nil, err
+	})
+	if !errors.Is(err, errBatchAborted) {
+		return response, err
+	}
+
+	// Nothing was applied, the operations before the failing one were rolled back
+	failed := response.Results[len(response.Results)-1]
+	aborted := &SpaceBatchError{Code: "batch_aborted", Message: fmt.Sprintf("Not applied because operation %d failed", failed.Index)}
+	results := make([]SpaceBatchResult, len(operations))
+	for i, operation := range operations {
+		results[i] = SpaceBatchResult{Index: i, Op: operation.Op, SpaceID: operation.SpaceID, Status: http.StatusConflict, Error: aborted}
+	}
+	results[failed.Index] = failed
+	return &SpaceBatchResponse{Failed: len(operations), Results: results}, nil
+}
+
+// runBatch applies the operations in order. With stopOnFailure it stops at the
+// first failing operation and returns errBatchAborted; unexpected errors such as
+// a lost connection are then returned as they are, so that a transaction can be
+// retried. Otherwise every failure is recorded in its result.
+func (s *SpaceServiceImpl) runBatch(ctx context.Context, operations []SpaceBatchOperation, stopOnFailure bool) (*SpaceBatchResponse, error) {
+	response := &SpaceBatchResponse{Results: make([]SpaceBatchResult, 0, len(operations))}
+	for i, operation := range operations {
+		result := SpaceBatchResult{Index: i, Op: operation.Op, SpaceID: operation.SpaceID, Status: http.StatusOK}
+
+		space, err := s.applyBatchOperation(ctx, operation)
+		if err != nil {
+			opErr := batchOperationError(err)
+			if stopOnFailure && (opErr.Kind == problem.KindInternal || opErr.Kind == problem.KindUnavailable) {
+				return nil, err
+			}
+			result.Status = opErr.Status()
+			result.Error = &SpaceBatchError{Code: opErr.Code, Message: opErr.Message, Fields: opErr.Fields}
+			response.Failed++
+			response.Results = append(response.Results, result)
+			if stopOnFailure {
+				return response, errBatchAborted
+			}
+			continue
+		}
+
+		if space == nil {
+			result.Status = http.StatusNoContent
+		}
+		result.Space = space
+		response.Succeeded++
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// applyBatchOperation applies an operation like the single-item endpoints do and
+// returns the updated space, or nil for a deleted one
+func (s *SpaceServiceImpl) applyBatchOperation(ctx context.Context, operation SpaceBatchOperation) (*Space, error) {
+	ctx, cancel := s.dbService.WriteContext(ctx)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"space_id": operation.SpaceID})
+	if operation.Op == BatchOpDelete {
+		return nil, s.deleteSpace(ctx, operation.SpaceID)
+	}
+
+	request := SpaceUpdateRequest{}
+	if operation.Op == BatchOpAssign {
+		request = SpaceUpdateRequest{AssignedTo: operation.AssignedTo, AssignedType: operation.AssignedType, AssignedID: operation.AssignedID}
+		if err := s.validateAssignment(ctx, &request); err != nil {
+			return nil, err
+		}
+		// An empty assignee would release the space instead
+		if request.AssignedTo == nil || *request.AssignedTo == "" {
+			return nil, errAssignedToRequired
+		}
+	}
+
+	space, err := s.spaces.FindOne(ctx, filter)
+	if err == mongo.ErrNoDocuments {
+		return nil, errSpaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *space
+	if operation.Op == BatchOpMaintenance {
+		space.SetMaintenance()
+	} else {
+		space.UpdateAssignment(request)
+	}
+	updated, err := s.spaces.UpdateAssignment(ctx, space, previous.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errSpaceChanged
+	}
+	return space, s.spaces.RecordAssignment(ctx, previous, space)
+}
+
+// batchOperationError maps the error of an operation to the problem the single-item endpoint reports
+func batchOperationError(err error) *problem.Error {
+	switch {
+	case errors.Is(err, errSpaceNotFound):
+		return problem.NotFound("space_not_found", errSpaceNotFound.Error())
+	case isAssignmentError(err):
+		return invalidReference(err)
+	default:
+		return problem.From(err)
+	}
+}
+
+// isTransactionUnsupported reports the error of a transaction on a standalone MongoDB server
+func isTransactionUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 20 // IllegalOperation
+}
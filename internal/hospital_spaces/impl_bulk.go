@@ -0,0 +1,191 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+)
+
+// Media types of bulk imports and exports
+const (
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// ImportSpaces creates spaces from a CSV or NDJSON file
+// @Summary Import hospital spaces
+// @Description Create a space for each row of a CSV file with a header row (columns name, type, floor, capacity and optionally floor_id and wing_id) or of an NDJSON file with one space creation object per line. The format is taken from the format query parameter or the Content-Type header. Every row is validated like a single space creation and rows are inserted in unordered batches, so invalid rows are reported with their line number without stopping the import. With dry_run=true the rows are only validated.
+// @Tags Spaces
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "File format, defaults to the Content-Type" Enums(csv, ndjson)
+// @Param dry_run query bool false "Only validate the rows"
+// @Param file body string true "CSV or NDJSON rows"
+// @Success 200 {object} ImportResult "Import summary with the rejected rows"
+// @Failure 400 {object} problem.Problem "Bad request - unsupported format or unreadable file"
+// @Failure 413 {object} problem.Problem "File too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces:import [post]
+func (s *SpaceServiceImpl) ImportSpaces(c *gin.Context) {
+	s.importRows(c, "spaces", s.LoadSpaces)
+}
+
+// ExportSpaces streams all spaces as CSV or NDJSON
+// @Summary Export hospital spaces
+// @Description Stream all spaces as CSV with a header row or as NDJSON with one space per line. The CSV columns include those of the import, so that an export can be imported elsewhere. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles.
+// @Tags Spaces
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Success 200 {string} string "Spaces in the requested format"
+// @Failure 400 {object} problem.Problem "Bad request - unsupported format"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces:export [get]
+func (s *SpaceServiceImpl) ExportSpaces(c *gin.Context) {
+	s.exportRows(c, "spaces", func(ctx context.Context, w http.ResponseWriter, format string) error {
+		return s.DumpSpaces(ctx, w, format, func(ctx context.Context, spaces []Space) error {
+			return s.presentSpaces(ctx, c, spaces)
+		})
+	})
+}
+
+// ImportAmbulances creates ambulances from a CSV or NDJSON file
+// @Summary Import ambulances
+// @Description Create an ambulance for each row of a CSV file with a header row (columns name, type and location) or of an NDJSON file with one ambulance creation object per line. Rows are validated and inserted like space imports; with dry_run=true the rows are only validated.
+// @Tags Ambulances
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "File format, defaults to the Content-Type" Enums(csv, ndjson)
+// @Param dry_run query bool false "Only validate the rows"
+// @Param file body string true "CSV or NDJSON rows"
+// @Success 200 {object} ImportResult "Import summary with the rejected rows"
+// @Failure 400 {object} problem.Problem "Bad request - unsupported format or unreadable file"
+// @Failure 413 {object} problem.Problem "File too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/ambulances:import [post]
+func (s *SpaceServiceImpl) ImportAmbulances(c *gin.Context) {
+	s.importRows(c, "ambulances", s.LoadAmbulances)
+}
+
+// ExportAmbulances streams all ambulances as CSV or NDJSON
+// @Summary Export ambulances
+// @Description Stream all ambulances as CSV with a header row or as NDJSON with one ambulance per line.
+// @Tags Ambulances
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Success 200 {string} string "Ambulances in the requested format"
+// @Failure 400 {object} problem.Problem "Bad request - unsupported format"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/ambulances:export [get]
+func (s *SpaceServiceImpl) ExportAmbulances(c *gin.Context) {
+	s.exportRows(c, "ambulances", func(ctx context.Context, w http.ResponseWriter, format string) error {
+		return s.DumpAmbulances(ctx, w, format)
+	})
+}
+
+// importRows runs an import of the request body and reports its summary
+func (s *SpaceServiceImpl) importRows(c *gin.Context, resource string, load func(context.Context, io.Reader, string, bool) (*ImportResult, error)) {
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+		format = formatOfMediaType(mediaType)
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		problem.Write(c, problem.Validation("unsupported_format", "The file must be CSV (text/csv) or NDJSON (application/x-ndjson)"))
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			problem.Write(c, problem.InvalidQuery("dry_run", "must be true or false"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	// Batches committed before a failure stay imported, the log records how far the import got
+	result, err := load(c.Request.Context(), c.Request.Body, format, dryRun)
+	if err != nil {
+		var sizeErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &sizeErr):
+			problem.Write(c, problem.TooLarge(sizeErr.Limit))
+		case IsInvalidImport(err):
+			problem.Write(c, problem.Validation("invalid_import_file", err.Error()))
+		default:
+			logging.FromContext(c.Request.Context()).Warn().Int("imported", result.Imported).Int("failed", result.Failed).Msgf("Import of %s aborted", resource)
+			problem.Write(c, problem.Internal(err, fmt.Sprintf("Failed to import %s", resource)))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// exportRows streams an export in the format of the format query parameter
+func (s *SpaceServiceImpl) exportRows(c *gin.Context, resource string, dump func(context.Context, http.ResponseWriter, string) error) {
+	format := c.DefaultQuery("format", FormatCSV)
+	contentType := map[string]string{FormatCSV: contentTypeCSV, FormatNDJSON: contentTypeNDJSON}[format]
+	if contentType == "" {
+		problem.Write(c, problem.InvalidQuery("format", "must be csv or ndjson"))
+		return
+	}
+
+	// Exports read the whole collection, so they get the longer aggregation timeout
+	ctx, cancel := s.dbService.AggregateContext(c.Request.Context())
+	defer cancel()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, resource, format))
+	c.Status(http.StatusOK)
+
+	if err := dump(ctx, c.Writer, format); err != nil {
+		if !c.Writer.Written() {
+			problem.Write(c, problem.Internal(err, fmt.Sprintf("Failed to export %s", resource)))
+			return
+		}
+		// The response is already streaming, the client sees a truncated body
+		_ = c.Error(err)
+		c.Abort()
+		logging.FromContext(c.Request.Context()).Error().Err(err).Msgf("Export of %s aborted", resource)
+	}
+}
+
+// formatOfMediaType returns the import format of a Content-Type
+func formatOfMediaType(mediaType string) string {
+	switch mediaType {
+	case contentTypeCSV, "application/csv":
+		return FormatCSV
+	case contentTypeNDJSON, "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON
+	default:
+		return ""
+	}
+}
+
+// customMethods dispatches custom methods such as /api/spaces:import. Gin cannot
+// match a literal colon, so the methods of a collection share a route whose last
+// segment ends in a parameter, and the parameter value selects the handler.
+func customMethods(param string, handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, ok := handlers[c.Param(param)]
+		if !ok {
+			problem.Write(c, problem.NotFound("route_not_found", "Route not found"))
+			return
+		}
+		handler(c)
+	}
+}
@@ -0,0 +1,269 @@
+package hospital_spaces
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// departmentSpacesFilter selects the spaces currently owned by a department
+func departmentSpacesFilter(departmentID string) bson.M {
+	return bson.M{"assigned_type": AssignedTypeDepartment, "assigned_id": departmentID}
+}
+
+// CreateDepartment creates a new department
+// @Summary Create a new department
+// @Description Register a new hospital department with its head and contact information
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Param department body DepartmentRequest true "Department creation details"
+// @Success 201 {object} Department "Department created successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments [post]
+func (s *SpaceServiceImpl) CreateDepartment(c *gin.Context) {
+	var request DepartmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	department := NewDepartment(request)
+	collection := s.dbService.GetCollection(collectionDepartments)
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	result, err := collection.InsertOne(ctx, department)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create department"))
+		return
+	}
+
+	department.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, department)
+}
+
+// GetDepartments retrieves all departments
+// @Summary Get all departments
+// @Description Retrieve a list of all hospital departments
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Success 200 {array} Department "List of departments"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments [get]
+func (s *SpaceServiceImpl) GetDepartments(c *gin.Context) {
+	collection := s.dbService.GetCollection(collectionDepartments)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve departments"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	departments := []Department{}
+	if err := cursor.All(ctx, &departments); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode departments"))
+		return
+	}
+
+	c.JSON(http.StatusOK, departments)
+}
+
+// GetDepartment retrieves a single department
+// @Summary Get a department
+// @Description Retrieve a department by its ID
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique department ID (UUID format)" format(uuid)
+// @Success 200 {object} Department "Department details"
+// @Failure 400 {object} problem.Problem "Bad request - invalid department ID"
+// @Failure 404 {object} problem.Problem "Department not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments/{id} [get]
+func (s *SpaceServiceImpl) GetDepartment(c *gin.Context) {
+	departmentID := c.Param("id")
+	if _, err := uuid.Parse(departmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_department_id", "Invalid department ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var department Department
+	err := s.dbService.GetCollection(collectionDepartments).FindOne(ctx, bson.M{"department_id": departmentID}).Decode(&department)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("department_not_found", errDepartmentNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find department"))
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// UpdateDepartment updates a department
+// @Summary Update a department
+// @Description Update the name, code, head and contact information of a department. Spaces owned by the department follow a name change.
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique department ID (UUID format)" format(uuid)
+// @Param department body DepartmentRequest true "Department update details"
+// @Success 200 {object} Department "Department updated successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid department ID or input"
+// @Failure 404 {object} problem.Problem "Department not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments/{id} [put]
+func (s *SpaceServiceImpl) UpdateDepartment(c *gin.Context) {
+	departmentID := c.Param("id")
+	if _, err := uuid.Parse(departmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_department_id", "Invalid department ID"))
+		return
+	}
+
+	var request DepartmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"name":       request.Name,
+			"code":       request.Code,
+			"head":       request.Head,
+			"contact":    request.Contact,
+			"updated_at": now,
+		},
+	}
+
+	var department Department
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := s.dbService.GetCollection(collectionDepartments).FindOneAndUpdate(ctx, bson.M{"department_id": departmentID}, update, opts).Decode(&department)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("department_not_found", errDepartmentNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update department"))
+		return
+	}
+
+	// Keep the denormalized department name of owned spaces in sync
+	if _, err := s.spaces.SetAssignedTo(ctx, departmentSpacesFilter(departmentID), department.Name); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to update spaces of department"))
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// DeleteDepartment deletes a department
+// @Summary Delete a department
+// @Description Remove a department. Deletion is rejected while spaces are still assigned to the department.
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique department ID (UUID format)" format(uuid)
+// @Success 204 "Department deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid department ID"
+// @Failure 404 {object} problem.Problem "Department not found"
+// @Failure 409 {object} problem.Problem "Department still owns spaces"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments/{id} [delete]
+func (s *SpaceServiceImpl) DeleteDepartment(c *gin.Context) {
+	departmentID := c.Param("id")
+	if _, err := uuid.Parse(departmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_department_id", "Invalid department ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	spaces, err := s.dbService.GetCollection(collectionSpaces).CountDocuments(ctx, notDeleted(departmentSpacesFilter(departmentID)))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check department spaces"))
+		return
+	}
+	if spaces > 0 {
+		problem.Write(c, problem.Conflict("department_has_spaces", fmt.Sprintf("Department still owns %d space(s)", spaces)))
+		return
+	}
+
+	result, err := s.dbService.GetCollection(collectionDepartments).DeleteOne(ctx, bson.M{"department_id": departmentID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete department"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("department_not_found", errDepartmentNotFound.Error()))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDepartmentSpaces retrieves the spaces owned by a department
+// @Summary Get the spaces of a department
+// @Description Retrieve the current footprint of a department, i.e. all spaces assigned to it
+// @Tags Departments
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique department ID (UUID format)" format(uuid)
+// @Success 200 {array} Space "Spaces owned by the department"
+// @Failure 400 {object} problem.Problem "Bad request - invalid department ID"
+// @Failure 404 {object} problem.Problem "Department not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/departments/{id}/spaces [get]
+func (s *SpaceServiceImpl) GetDepartmentSpaces(c *gin.Context) {
+	departmentID := c.Param("id")
+	if _, err := uuid.Parse(departmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_department_id", "Invalid department ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	exists, err := s.exists(ctx, collectionDepartments, bson.M{"department_id": departmentID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find department"))
+		return
+	}
+	if !exists {
+		problem.Write(c, problem.NotFound("department_not_found", errDepartmentNotFound.Error()))
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "floor", Value: 1}, {Key: "name", Value: 1}})
+	spaces, err := s.spaces.Find(ctx, notDeleted(departmentSpacesFilter(departmentID)), opts)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve spaces"))
+		return
+	}
+
+	c.JSON(http.StatusOK, spaces)
+}
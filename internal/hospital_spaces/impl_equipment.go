@@ -0,0 +1,438 @@
+package hospital_spaces
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errEquipmentNotFound = errors.New("Equipment not found")
+	errSpaceNotFound     = errors.New("Space not found")
+)
+
+// CreateEquipment registers new equipment
+// @Summary Register new equipment
+// @Description Register a new piece of equipment, optionally placing it in a space
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param equipment body EquipmentCreateRequest true "Equipment creation details"
+// @Success 201 {object} Equipment "Equipment created successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input or unknown space"
+// @Failure 409 {object} problem.Problem "Serial number already registered"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment [post]
+func (s *SpaceServiceImpl) CreateEquipment(c *gin.Context) {
+	var request EquipmentCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	if request.SpaceID != nil {
+		exists, err := s.exists(ctx, collectionSpaces, notDeleted(bson.M{"space_id": *request.SpaceID}))
+		if err != nil {
+			problem.Write(c, problem.Internal(err, "Failed to find space"))
+			return
+		}
+		if !exists {
+			problem.Write(c, problem.Validation("space_not_found", errSpaceNotFound.Error()))
+			return
+		}
+	}
+
+	equipment := NewEquipment(request)
+	result, err := s.dbService.GetCollection(collectionEquipment).InsertOne(ctx, equipment)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			problem.Write(c, problem.Conflict("duplicate_serial_number", "Equipment with this serial number already exists"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to create equipment"))
+		return
+	}
+	equipment.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Record the initial placement so the location history is complete
+	if equipment.SpaceID != nil {
+		movement := &EquipmentMovement{
+			EquipmentID: equipment.EquipmentID,
+			ToSpaceID:   equipment.SpaceID,
+			Reason:      "registered",
+			MovedAt:     equipment.CreatedAt,
+		}
+		if _, err := s.dbService.GetCollection(collectionEquipmentMovements).InsertOne(ctx, movement); err != nil {
+			problem.Write(c, problem.Internal(err, "Failed to record equipment placement"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, equipment)
+}
+
+// GetEquipment retrieves all equipment
+// @Summary Get all equipment
+// @Description Retrieve the equipment inventory, optionally filtered by category, status, space or maintenance due date
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param category query string false "Only return equipment of this category"
+// @Param status query string false "Only return equipment with this status"
+// @Param space_id query string false "Only return equipment located in this space" format(uuid)
+// @Param maintenance_due_before query string false "Only return equipment with maintenance due before this time (RFC 3339)" format(date-time)
+// @Success 200 {array} Equipment "List of equipment"
+// @Failure 400 {object} problem.Problem "Bad request - invalid filter"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment [get]
+func (s *SpaceServiceImpl) GetEquipment(c *gin.Context) {
+	filter := bson.M{}
+	if category := c.Query("category"); category != "" {
+		filter["category"] = category
+	}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if spaceID := c.Query("space_id"); spaceID != "" {
+		filter["space_id"] = spaceID
+	}
+	if dueBefore := c.Query("maintenance_due_before"); dueBefore != "" {
+		due, err := time.Parse(time.RFC3339, dueBefore)
+		if err != nil {
+			problem.Write(c, problem.InvalidQuery("maintenance_due_before", "must be an RFC 3339 time"))
+			return
+		}
+		filter["maintenance_due_at"] = bson.M{"$lt": due}
+	}
+
+	collection := s.dbService.GetCollection(collectionEquipment)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "name", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve equipment"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	equipment := []Equipment{}
+	if err := cursor.All(ctx, &equipment); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode equipment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// GetEquipmentItem retrieves a single piece of equipment
+// @Summary Get equipment
+// @Description Retrieve a piece of equipment by its ID
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique equipment ID (UUID format)" format(uuid)
+// @Success 200 {object} Equipment "Equipment details"
+// @Failure 400 {object} problem.Problem "Bad request - invalid equipment ID"
+// @Failure 404 {object} problem.Problem "Equipment not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment/{id} [get]
+func (s *SpaceServiceImpl) GetEquipmentItem(c *gin.Context) {
+	equipmentID := c.Param("id")
+	if _, err := uuid.Parse(equipmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_equipment_id", "Invalid equipment ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var equipment Equipment
+	err := s.dbService.GetCollection(collectionEquipment).FindOne(ctx, bson.M{"equipment_id": equipmentID}).Decode(&equipment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("equipment_not_found", errEquipmentNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find equipment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// UpdateEquipment updates equipment details
+// @Summary Update equipment
+// @Description Update the name, serial number, category, status and maintenance due date of equipment. Use the move endpoint to change its location.
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique equipment ID (UUID format)" format(uuid)
+// @Param equipment body EquipmentUpdateRequest true "Equipment update details"
+// @Success 200 {object} Equipment "Equipment updated successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid equipment ID or input"
+// @Failure 404 {object} problem.Problem "Equipment not found"
+// @Failure 409 {object} problem.Problem "Serial number already registered"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment/{id} [put]
+func (s *SpaceServiceImpl) UpdateEquipment(c *gin.Context) {
+	equipmentID := c.Param("id")
+	if _, err := uuid.Parse(equipmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_equipment_id", "Invalid equipment ID"))
+		return
+	}
+
+	var request EquipmentUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":               request.Name,
+			"serial_number":      request.SerialNumber,
+			"category":           request.Category,
+			"status":             request.Status,
+			"maintenance_due_at": request.MaintenanceDueAt,
+			"updated_at":         time.Now(),
+		},
+	}
+
+	var equipment Equipment
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := s.dbService.GetCollection(collectionEquipment).FindOneAndUpdate(ctx, bson.M{"equipment_id": equipmentID}, update, opts).Decode(&equipment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("equipment_not_found", errEquipmentNotFound.Error()))
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			problem.Write(c, problem.Conflict("duplicate_serial_number", "Equipment with this serial number already exists"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update equipment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// DeleteEquipment deletes equipment
+// @Summary Delete equipment
+// @Description Remove a piece of equipment from the inventory. Its location history is kept.
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique equipment ID (UUID format)" format(uuid)
+// @Success 204 "Equipment deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid equipment ID"
+// @Failure 404 {object} problem.Problem "Equipment not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment/{id} [delete]
+func (s *SpaceServiceImpl) DeleteEquipment(c *gin.Context) {
+	equipmentID := c.Param("id")
+	if _, err := uuid.Parse(equipmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_equipment_id", "Invalid equipment ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	result, err := s.dbService.GetCollection(collectionEquipment).DeleteOne(ctx, bson.M{"equipment_id": equipmentID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete equipment"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("equipment_not_found", errEquipmentNotFound.Error()))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MoveEquipment moves equipment to another space
+// @Summary Move equipment between spaces
+// @Description Move a piece of equipment to another space, or out of any space when space_id is omitted. Every move is recorded in the equipment history.
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique equipment ID (UUID format)" format(uuid)
+// @Param move body EquipmentMoveRequest true "Target space and reason"
+// @Success 200 {object} Equipment "Equipment moved successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid equipment ID, input or unknown space"
+// @Failure 404 {object} problem.Problem "Equipment not found"
+// @Failure 409 {object} problem.Problem "Equipment was moved concurrently"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment/{id}/move [post]
+func (s *SpaceServiceImpl) MoveEquipment(c *gin.Context) {
+	equipmentID := c.Param("id")
+	if _, err := uuid.Parse(equipmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_equipment_id", "Invalid equipment ID"))
+		return
+	}
+
+	var request EquipmentMoveRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	collection := s.dbService.GetCollection(collectionEquipment)
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	var equipment Equipment
+	filter := bson.M{"equipment_id": equipmentID}
+	if err := collection.FindOne(ctx, filter).Decode(&equipment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("equipment_not_found", errEquipmentNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find equipment"))
+		return
+	}
+
+	if request.SpaceID != nil {
+		exists, err := s.exists(ctx, collectionSpaces, notDeleted(bson.M{"space_id": *request.SpaceID}))
+		if err != nil {
+			problem.Write(c, problem.Internal(err, "Failed to find space"))
+			return
+		}
+		if !exists {
+			problem.Write(c, problem.Validation("space_not_found", errSpaceNotFound.Error()))
+			return
+		}
+	}
+
+	movement := equipment.MoveTo(request.SpaceID, request.Reason)
+	update := bson.M{"$set": bson.M{"updated_at": equipment.UpdatedAt}}
+	if equipment.SpaceID != nil {
+		update["$set"].(bson.M)["space_id"] = equipment.SpaceID
+	} else {
+		update["$unset"] = bson.M{"space_id": ""}
+	}
+
+	// Guard against a concurrent move by matching the previous location
+	filter["space_id"] = movement.FromSpaceID
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to move equipment"))
+		return
+	}
+	if result.MatchedCount == 0 {
+		problem.Write(c, problem.Conflict("concurrent_move", "Equipment was moved concurrently, please retry"))
+		return
+	}
+
+	if _, err := s.dbService.GetCollection(collectionEquipmentMovements).InsertOne(ctx, movement); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to record equipment movement"))
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// GetEquipmentHistory retrieves the location history of equipment
+// @Summary Get equipment history
+// @Description Retrieve all recorded moves of a piece of equipment, newest first
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique equipment ID (UUID format)" format(uuid)
+// @Success 200 {array} EquipmentMovement "Location history"
+// @Failure 400 {object} problem.Problem "Bad request - invalid equipment ID"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/equipment/{id}/history [get]
+func (s *SpaceServiceImpl) GetEquipmentHistory(c *gin.Context) {
+	equipmentID := c.Param("id")
+	if _, err := uuid.Parse(equipmentID); err != nil {
+		problem.Write(c, problem.Validation("invalid_equipment_id", "Invalid equipment ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "moved_at", Value: -1}})
+	cursor, err := s.dbService.GetCollection(collectionEquipmentMovements).Find(ctx, bson.M{"equipment_id": equipmentID}, opts)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve equipment history"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	movements := []EquipmentMovement{}
+	if err := cursor.All(ctx, &movements); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode equipment history"))
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
+
+// GetSpaceEquipment retrieves the equipment currently held by a space
+// @Summary Get the equipment of a space
+// @Description Retrieve all equipment currently located in a space
+// @Tags Spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique space ID (UUID format)" format(uuid)
+// @Success 200 {array} Equipment "Equipment in the space"
+// @Failure 400 {object} problem.Problem "Bad request - invalid space ID"
+// @Failure 404 {object} problem.Problem "Space not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces/{id}/equipment [get]
+func (s *SpaceServiceImpl) GetSpaceEquipment(c *gin.Context) {
+	spaceIDStr := c.Param("id")
+	if _, err := uuid.Parse(spaceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_space_id", "Invalid space ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	exists, err := s.exists(ctx, collectionSpaces, notDeleted(bson.M{"space_id": spaceIDStr}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find space"))
+		return
+	}
+	if !exists {
+		problem.Write(c, problem.NotFound("space_not_found", errSpaceNotFound.Error()))
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := s.dbService.GetCollection(collectionEquipment).Find(ctx, bson.M{"space_id": spaceIDStr}, opts)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve equipment"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	equipment := []Equipment{}
+	if err := cursor.All(ctx, &equipment); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode equipment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
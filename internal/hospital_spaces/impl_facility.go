@@ -0,0 +1,974 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errFloorNotFound  = errors.New("Floor not found")
+	errWingNotFound   = errors.New("Wing not found")
+	errWingNotOnFloor = errors.New("Wing does not belong to the given floor")
+)
+
+// CreateBuilding creates a new building
+// @Summary Create a new building
+// @Description Register a new building of the hospital facility
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param building body BuildingRequest true "Building creation details"
+// @Success 201 {object} Building "Building created successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/buildings [post]
+func (s *SpaceServiceImpl) CreateBuilding(c *gin.Context) {
+	var request BuildingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	building := NewBuilding(request)
+	collection := s.dbService.GetCollection(collectionBuildings)
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	result, err := collection.InsertOne(ctx, building)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create building"))
+		return
+	}
+
+	building.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, building)
+}
+
+// GetBuildings retrieves all buildings
+// @Summary Get all buildings
+// @Description Retrieve a list of all buildings of the hospital facility
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Success 200 {array} Building "List of buildings"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/buildings [get]
+func (s *SpaceServiceImpl) GetBuildings(c *gin.Context) {
+	collection := s.dbService.GetCollection(collectionBuildings)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve buildings"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	buildings := []Building{}
+	if err := cursor.All(ctx, &buildings); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode buildings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, buildings)
+}
+
+// GetBuilding retrieves a single building
+// @Summary Get a building
+// @Description Retrieve a building by its ID
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique building ID (UUID format)" format(uuid)
+// @Success 200 {object} Building "Building details"
+// @Failure 400 {object} problem.Problem "Bad request - invalid building ID"
+// @Failure 404 {object} problem.Problem "Building not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/buildings/{id} [get]
+func (s *SpaceServiceImpl) GetBuilding(c *gin.Context) {
+	buildingID := c.Param("id")
+	if _, err := uuid.Parse(buildingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_building_id", "Invalid building ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var building Building
+	err := s.dbService.GetCollection(collectionBuildings).FindOne(ctx, bson.M{"building_id": buildingID}).Decode(&building)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("building_not_found", "Building not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find building"))
+		return
+	}
+
+	c.JSON(http.StatusOK, building)
+}
+
+// UpdateBuilding updates a building
+// @Summary Update a building
+// @Description Update the name, code and address of a building
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique building ID (UUID format)" format(uuid)
+// @Param building body BuildingRequest true "Building update details"
+// @Success 200 {object} Building "Building updated successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid building ID or input"
+// @Failure 404 {object} problem.Problem "Building not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/buildings/{id} [put]
+func (s *SpaceServiceImpl) UpdateBuilding(c *gin.Context) {
+	buildingID := c.Param("id")
+	if _, err := uuid.Parse(buildingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_building_id", "Invalid building ID"))
+		return
+	}
+
+	var request BuildingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	collection := s.dbService.GetCollection(collectionBuildings)
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       request.Name,
+			"code":       request.Code,
+			"address":    request.Address,
+			"updated_at": time.Now(),
+		},
+	}
+
+	var building Building
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(ctx, bson.M{"building_id": buildingID}, update, opts).Decode(&building)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("building_not_found", "Building not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update building"))
+		return
+	}
+
+	c.JSON(http.StatusOK, building)
+}
+
+// DeleteBuilding deletes a building
+// @Summary Delete a building
+// @Description Remove a building. Deletion is rejected while floors still belong to the building.
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique building ID (UUID format)" format(uuid)
+// @Success 204 "Building deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid building ID"
+// @Failure 404 {object} problem.Problem "Building not found"
+// @Failure 409 {object} problem.Problem "Building still has floors"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/buildings/{id} [delete]
+func (s *SpaceServiceImpl) DeleteBuilding(c *gin.Context) {
+	buildingID := c.Param("id")
+	if _, err := uuid.Parse(buildingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_building_id", "Invalid building ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	floors, err := s.dbService.GetCollection(collectionFloors).CountDocuments(ctx, bson.M{"building_id": buildingID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check building floors"))
+		return
+	}
+	if floors > 0 {
+		problem.Write(c, problem.Conflict("building_has_floors", fmt.Sprintf("Building still has %d floor(s)", floors)))
+		return
+	}
+
+	result, err := s.dbService.GetCollection(collectionBuildings).DeleteOne(ctx, bson.M{"building_id": buildingID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete building"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("building_not_found", "Building not found"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateFloor creates a new floor
+// @Summary Create a new floor
+// @Description Register a new floor within an existing building
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param floor body FloorRequest true "Floor creation details"
+// @Success 201 {object} Floor "Floor created successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input or unknown building"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/floors [post]
+func (s *SpaceServiceImpl) CreateFloor(c *gin.Context) {
+	var request FloorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	exists, err := s.exists(ctx, collectionBuildings, bson.M{"building_id": request.BuildingID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find building"))
+		return
+	}
+	if !exists {
+		problem.Write(c, problem.Validation("building_not_found", "Building not found"))
+		return
+	}
+
+	floor := NewFloor(request)
+	result, err := s.dbService.GetCollection(collectionFloors).InsertOne(ctx, floor)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create floor"))
+		return
+	}
+
+	floor.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, floor)
+}
+
+// GetFloors retrieves all floors
+// @Summary Get all floors
+// @Description Retrieve a list of floors, optionally limited to a single building
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param building_id query string false "Only return floors of this building" format(uuid)
+// @Success 200 {array} Floor "List of floors"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/floors [get]
+func (s *SpaceServiceImpl) GetFloors(c *gin.Context) {
+	filter := bson.M{}
+	if buildingID := c.Query("building_id"); buildingID != "" {
+		filter["building_id"] = buildingID
+	}
+
+	collection := s.dbService.GetCollection(collectionFloors)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "building_id", Value: 1}, {Key: "level", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve floors"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	floors := []Floor{}
+	if err := cursor.All(ctx, &floors); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode floors"))
+		return
+	}
+
+	c.JSON(http.StatusOK, floors)
+}
+
+// GetFloor retrieves a single floor
+// @Summary Get a floor
+// @Description Retrieve a floor by its ID
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique floor ID (UUID format)" format(uuid)
+// @Success 200 {object} Floor "Floor details"
+// @Failure 400 {object} problem.Problem "Bad request - invalid floor ID"
+// @Failure 404 {object} problem.Problem "Floor not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/floors/{id} [get]
+func (s *SpaceServiceImpl) GetFloor(c *gin.Context) {
+	floorID := c.Param("id")
+	if _, err := uuid.Parse(floorID); err != nil {
+		problem.Write(c, problem.Validation("invalid_floor_id", "Invalid floor ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var floor Floor
+	err := s.dbService.GetCollection(collectionFloors).FindOne(ctx, bson.M{"floor_id": floorID}).Decode(&floor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("floor_not_found", "Floor not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find floor"))
+		return
+	}
+
+	c.JSON(http.StatusOK, floor)
+}
+
+// UpdateFloor updates a floor
+// @Summary Update a floor
+// @Description Update the building, level and name of a floor. Spaces on the floor follow the change.
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique floor ID (UUID format)" format(uuid)
+// @Param floor body FloorRequest true "Floor update details"
+// @Success 200 {object} Floor "Floor updated successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid floor ID, input or unknown building"
+// @Failure 404 {object} problem.Problem "Floor not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/floors/{id} [put]
+func (s *SpaceServiceImpl) UpdateFloor(c *gin.Context) {
+	floorID := c.Param("id")
+	if _, err := uuid.Parse(floorID); err != nil {
+		problem.Write(c, problem.Validation("invalid_floor_id", "Invalid floor ID"))
+		return
+	}
+
+	var request FloorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	exists, err := s.exists(ctx, collectionBuildings, bson.M{"building_id": request.BuildingID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find building"))
+		return
+	}
+	if !exists {
+		problem.Write(c, problem.Validation("building_not_found", "Building not found"))
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"building_id": request.BuildingID,
+			"level":       request.Level,
+			"name":        request.Name,
+			"updated_at":  now,
+		},
+	}
+
+	var floor Floor
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = s.dbService.GetCollection(collectionFloors).FindOneAndUpdate(ctx, bson.M{"floor_id": floorID}, update, opts).Decode(&floor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("floor_not_found", "Floor not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update floor"))
+		return
+	}
+
+	// Keep the denormalized building and level of the spaces in sync. updated_at
+	// marks the last change of the assignment, see SpaceRepository.SetAssignedTo.
+	spacesUpdate := bson.M{
+		"$set": bson.M{
+			"building_id": floor.BuildingID,
+			"floor":       floor.Level,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	if _, err := s.dbService.GetCollection(collectionSpaces).UpdateMany(ctx, bson.M{"floor_id": floorID}, spacesUpdate); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to update spaces of floor"))
+		return
+	}
+
+	c.JSON(http.StatusOK, floor)
+}
+
+// DeleteFloor deletes a floor
+// @Summary Delete a floor
+// @Description Remove a floor. Deletion is rejected while spaces or wings still reference the floor.
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique floor ID (UUID format)" format(uuid)
+// @Success 204 "Floor deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid floor ID"
+// @Failure 404 {object} problem.Problem "Floor not found"
+// @Failure 409 {object} problem.Problem "Floor is still referenced by spaces or wings"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/floors/{id} [delete]
+func (s *SpaceServiceImpl) DeleteFloor(c *gin.Context) {
+	floorID := c.Param("id")
+	if _, err := uuid.Parse(floorID); err != nil {
+		problem.Write(c, problem.Validation("invalid_floor_id", "Invalid floor ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	spaces, err := s.dbService.GetCollection(collectionSpaces).CountDocuments(ctx, notDeleted(bson.M{"floor_id": floorID}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check floor spaces"))
+		return
+	}
+	if spaces > 0 {
+		problem.Write(c, problem.Conflict("floor_has_spaces", fmt.Sprintf("Floor is still referenced by %d space(s)", spaces)))
+		return
+	}
+
+	wings, err := s.dbService.GetCollection(collectionWings).CountDocuments(ctx, bson.M{"floor_id": floorID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check floor wings"))
+		return
+	}
+	if wings > 0 {
+		problem.Write(c, problem.Conflict("floor_has_wings", fmt.Sprintf("Floor still has %d wing(s)", wings)))
+		return
+	}
+
+	result, err := s.dbService.GetCollection(collectionFloors).DeleteOne(ctx, bson.M{"floor_id": floorID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete floor"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("floor_not_found", "Floor not found"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateWing creates a new wing
+// @Summary Create a new wing
+// @Description Register a new wing on an existing floor
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param wing body WingRequest true "Wing creation details"
+// @Success 201 {object} Wing "Wing created successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input or unknown floor"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/wings [post]
+func (s *SpaceServiceImpl) CreateWing(c *gin.Context) {
+	var request WingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	exists, err := s.exists(ctx, collectionFloors, bson.M{"floor_id": request.FloorID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find floor"))
+		return
+	}
+	if !exists {
+		problem.Write(c, problem.Validation("floor_not_found", errFloorNotFound.Error()))
+		return
+	}
+
+	wing := NewWing(request)
+	result, err := s.dbService.GetCollection(collectionWings).InsertOne(ctx, wing)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create wing"))
+		return
+	}
+
+	wing.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, wing)
+}
+
+// GetWings retrieves all wings
+// @Summary Get all wings
+// @Description Retrieve a list of wings, optionally limited to a single floor
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param floor_id query string false "Only return wings of this floor" format(uuid)
+// @Success 200 {array} Wing "List of wings"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/wings [get]
+func (s *SpaceServiceImpl) GetWings(c *gin.Context) {
+	filter := bson.M{}
+	if floorID := c.Query("floor_id"); floorID != "" {
+		filter["floor_id"] = floorID
+	}
+
+	collection := s.dbService.GetCollection(collectionWings)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "floor_id", Value: 1}, {Key: "name", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve wings"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	wings := []Wing{}
+	if err := cursor.All(ctx, &wings); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode wings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, wings)
+}
+
+// GetWing retrieves a single wing
+// @Summary Get a wing
+// @Description Retrieve a wing by its ID
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique wing ID (UUID format)" format(uuid)
+// @Success 200 {object} Wing "Wing details"
+// @Failure 400 {object} problem.Problem "Bad request - invalid wing ID"
+// @Failure 404 {object} problem.Problem "Wing not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/wings/{id} [get]
+func (s *SpaceServiceImpl) GetWing(c *gin.Context) {
+	wingID := c.Param("id")
+	if _, err := uuid.Parse(wingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_wing_id", "Invalid wing ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var wing Wing
+	err := s.dbService.GetCollection(collectionWings).FindOne(ctx, bson.M{"wing_id": wingID}).Decode(&wing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("wing_not_found", "Wing not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find wing"))
+		return
+	}
+
+	c.JSON(http.StatusOK, wing)
+}
+
+// UpdateWing updates a wing
+// @Summary Update a wing
+// @Description Update the floor and name of a wing. Spaces in the wing move to the new floor.
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique wing ID (UUID format)" format(uuid)
+// @Param wing body WingRequest true "Wing update details"
+// @Success 200 {object} Wing "Wing updated successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid wing ID, input or unknown floor"
+// @Failure 404 {object} problem.Problem "Wing not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/wings/{id} [put]
+func (s *SpaceServiceImpl) UpdateWing(c *gin.Context) {
+	wingID := c.Param("id")
+	if _, err := uuid.Parse(wingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_wing_id", "Invalid wing ID"))
+		return
+	}
+
+	var request WingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	var floor Floor
+	err := s.dbService.GetCollection(collectionFloors).FindOne(ctx, bson.M{"floor_id": request.FloorID}).Decode(&floor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.Validation("floor_not_found", errFloorNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find floor"))
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"floor_id":   request.FloorID,
+			"name":       request.Name,
+			"updated_at": now,
+		},
+	}
+
+	var wing Wing
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = s.dbService.GetCollection(collectionWings).FindOneAndUpdate(ctx, bson.M{"wing_id": wingID}, update, opts).Decode(&wing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("wing_not_found", "Wing not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to update wing"))
+		return
+	}
+
+	// Spaces in the wing follow it to its (possibly new) floor, keeping updated_at
+	// as the last change of their assignment
+	spacesUpdate := bson.M{
+		"$set": bson.M{
+			"building_id": floor.BuildingID,
+			"floor_id":    floor.FloorID,
+			"floor":       floor.Level,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	if _, err := s.dbService.GetCollection(collectionSpaces).UpdateMany(ctx, bson.M{"wing_id": wingID}, spacesUpdate); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to update spaces of wing"))
+		return
+	}
+
+	c.JSON(http.StatusOK, wing)
+}
+
+// DeleteWing deletes a wing
+// @Summary Delete a wing
+// @Description Remove a wing. Deletion is rejected while spaces still reference the wing.
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique wing ID (UUID format)" format(uuid)
+// @Success 204 "Wing deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid wing ID"
+// @Failure 404 {object} problem.Problem "Wing not found"
+// @Failure 409 {object} problem.Problem "Wing is still referenced by spaces"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/wings/{id} [delete]
+func (s *SpaceServiceImpl) DeleteWing(c *gin.Context) {
+	wingID := c.Param("id")
+	if _, err := uuid.Parse(wingID); err != nil {
+		problem.Write(c, problem.Validation("invalid_wing_id", "Invalid wing ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	spaces, err := s.dbService.GetCollection(collectionSpaces).CountDocuments(ctx, notDeleted(bson.M{"wing_id": wingID}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check wing spaces"))
+		return
+	}
+	if spaces > 0 {
+		problem.Write(c, problem.Conflict("wing_has_spaces", fmt.Sprintf("Wing is still referenced by %d space(s)", spaces)))
+		return
+	}
+
+	result, err := s.dbService.GetCollection(collectionWings).DeleteOne(ctx, bson.M{"wing_id": wingID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete wing"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("wing_not_found", "Wing not found"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateSpaceLocation moves a space within the facility hierarchy
+// @Summary Move a space to a floor and wing
+// @Description Reference a space from a floor and optionally a wing of that floor
+// @Tags Spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "The unique space ID (UUID format)" format(uuid)
+// @Param location body SpaceLocationRequest true "Target floor and wing"
+// @Success 200 {object} Space "Space moved successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid space ID, input, floor or wing"
+// @Failure 404 {object} problem.Problem "Space not found"
+// @Failure 409 {object} problem.Problem "The space was changed or deleted concurrently"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces/{id}/location [put]
+func (s *SpaceServiceImpl) UpdateSpaceLocation(c *gin.Context) {
+	spaceIDStr := c.Param("id")
+	if _, err := uuid.Parse(spaceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_space_id", "Invalid space ID"))
+		return
+	}
+
+	var request SpaceLocationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	filter := notDeleted(bson.M{"space_id": spaceIDStr})
+	space, err := s.spaces.FindOne(ctx, filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("space_not_found", "Space not found"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find space"))
+		return
+	}
+
+	floor, wing, err := s.resolveLocation(ctx, &request.FloorID, request.WingID)
+	if err != nil {
+		if isLocationError(err) {
+			problem.Write(c, invalidReference(err))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to resolve location"))
+		return
+	}
+
+	// Only the version read is updated, a concurrent change is not overwritten
+	filter["version"] = space.Version
+	space.SetLocation(floor, wing)
+	space.Version++
+	space.UpdatedAt = time.Now()
+	set := bson.M{
+		"building_id": space.BuildingID,
+		"floor_id":    space.FloorID,
+		"floor":       space.Floor,
+		"updated_at":  space.UpdatedAt,
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if space.WingID != nil {
+		set["wing_id"] = space.WingID
+	} else {
+		update["$unset"] = bson.M{"wing_id": ""}
+	}
+
+	result, err := s.dbService.GetCollection(collectionSpaces).UpdateOne(ctx, filter, update)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to update space"))
+		return
+	}
+	if result.MatchedCount == 0 {
+		problem.Write(c, errSpaceChanged)
+		return
+	}
+
+	if err := s.presentSpace(ctx, c, space); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to resolve patient placement"))
+		return
+	}
+
+	c.JSON(http.StatusOK, space)
+}
+
+// GetFacilityTree returns the building / floor / wing hierarchy with occupancy
+// @Summary Get the facility tree
+// @Description Retrieve all buildings with their floors and wings, each node carrying the aggregated occupancy of the spaces below it
+// @Tags Facility
+// @Accept json
+// @Produce json
+// @Success 200 {array} BuildingNode "Facility hierarchy"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/facility/tree [get]
+func (s *SpaceServiceImpl) GetFacilityTree(c *gin.Context) {
+	ctx, cancel := s.dbService.AggregateContext(c.Request.Context())
+	defer cancel()
+
+	var buildings []Building
+	if err := s.findAll(ctx, collectionBuildings, &buildings); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve buildings"))
+		return
+	}
+	var floors []Floor
+	if err := s.findAll(ctx, collectionFloors, &floors); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve floors"))
+		return
+	}
+	var wings []Wing
+	if err := s.findAll(ctx, collectionWings, &wings); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve wings"))
+		return
+	}
+
+	floorOccupancy, wingOccupancy, err := s.locationOccupancy(ctx)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to aggregate occupancy"))
+		return
+	}
+
+	wingsByFloor := map[string][]WingNode{}
+	for _, wing := range wings {
+		node := WingNode{Wing: wing, Occupancy: wingOccupancy[wing.WingID]}
+		node.Occupancy.computeRate()
+		wingsByFloor[wing.FloorID] = append(wingsByFloor[wing.FloorID], node)
+	}
+
+	floorsByBuilding := map[string][]FloorNode{}
+	for _, floor := range floors {
+		node := FloorNode{Floor: floor, Occupancy: floorOccupancy[floor.FloorID], Wings: wingsByFloor[floor.FloorID]}
+		if node.Wings == nil {
+			node.Wings = []WingNode{}
+		}
+		node.Occupancy.computeRate()
+		floorsByBuilding[floor.BuildingID] = append(floorsByBuilding[floor.BuildingID], node)
+	}
+
+	tree := make([]BuildingNode, 0, len(buildings))
+	for _, building := range buildings {
+		node := BuildingNode{Building: building, Floors: floorsByBuilding[building.BuildingID]}
+		if node.Floors == nil {
+			node.Floors = []FloorNode{}
+		}
+		sort.Slice(node.Floors, func(i, j int) bool { return node.Floors[i].Level < node.Floors[j].Level })
+		for _, floor := range node.Floors {
+			node.Occupancy.add(floor.Occupancy)
+		}
+		node.Occupancy.computeRate()
+		tree = append(tree, node)
+	}
+	sort.Slice(tree, func(i, j int) bool { return tree[i].Name < tree[j].Name })
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// locationOccupancy aggregates space occupancy per floor and per wing
+func (s *SpaceServiceImpl) locationOccupancy(ctx context.Context) (map[string]Occupancy, map[string]Occupancy, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{"floor_id": bson.M{"$ne": nil}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"floor_id": "$floor_id", "wing_id": "$wing_id", "status": "$status"},
+			"spaces":   bson.M{"$sum": 1},
+			"capacity": bson.M{"$sum": "$capacity"},
+		}}},
+	}
+
+	cursor, err := s.dbService.GetCollection(collectionSpaces).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			FloorID string  `bson:"floor_id"`
+			WingID  *string `bson:"wing_id"`
+			Status  string  `bson:"status"`
+		} `bson:"_id"`
+		Spaces   int `bson:"spaces"`
+		Capacity int `bson:"capacity"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, nil, err
+	}
+
+	floors := map[string]Occupancy{}
+	wings := map[string]Occupancy{}
+	for _, row := range rows {
+		occupancy := statusOccupancy(row.ID.Status, row.Spaces, row.Capacity)
+
+		floor := floors[row.ID.FloorID]
+		floor.add(occupancy)
+		floors[row.ID.FloorID] = floor
+
+		if row.ID.WingID != nil {
+			wing := wings[*row.ID.WingID]
+			wing.add(occupancy)
+			wings[*row.ID.WingID] = wing
+		}
+	}
+
+	return floors, wings, nil
+}
+
+// resolveLocation loads the referenced floor and wing, deriving the floor from the wing when only a wing is given
+func (s *SpaceServiceImpl) resolveLocation(ctx context.Context, floorID, wingID *string) (*Floor, *Wing, error) {
+	var wing *Wing
+	if wingID != nil {
+		wing = &Wing{}
+		err := s.dbService.GetCollection(collectionWings).FindOne(ctx, bson.M{"wing_id": *wingID}).Decode(wing)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, errWingNotFound
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if floorID == nil {
+			floorID = &wing.FloorID
+		} else if *floorID != wing.FloorID {
+			return nil, nil, errWingNotOnFloor
+		}
+	}
+
+	var floor Floor
+	err := s.dbService.GetCollection(collectionFloors).FindOne(ctx, bson.M{"floor_id": *floorID}).Decode(&floor)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil, errFloorNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &floor, wing, nil
+}
+
+// isLocationError reports whether err is caused by an invalid floor or wing reference
+func isLocationError(err error) bool {
+	return errors.Is(err, errFloorNotFound) || errors.Is(err, errWingNotFound) || errors.Is(err, errWingNotOnFloor)
+}
+
+// exists reports whether a document matching the filter exists in the collection
+func (s *SpaceServiceImpl) exists(ctx context.Context, collectionName string, filter bson.M) (bool, error) {
+	count, err := s.dbService.GetCollection(collectionName).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// findAll decodes all documents of a collection into results
+func (s *SpaceServiceImpl) findAll(ctx context.Context, collectionName string, results interface{}) error {
+	cursor, err := s.dbService.GetCollection(collectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
@@ -0,0 +1,193 @@
+package hospital_spaces
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/forecast"
+	"github.com/rosadsky/ros-project-backend/internal/migrations"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// forecastLookback is how much assignment history the forecast averages
+	forecastLookback = 8 * 7 * 24 * time.Hour
+	// defaultForecastHorizon is the forecast period when horizon is not given
+	defaultForecastHorizon = 72 * time.Hour
+	// maxForecastHorizon bounds the forecast period
+	maxForecastHorizon = 14 * 24 * time.Hour
+)
+
+// GetOccupancyForecast forecasts hourly occupancy from the assignment history
+// @Summary Forecast space occupancy
+// @Description Predict the number of occupied spaces for every hour of the horizon, for one space type or all spaces, with 95% prediction intervals. The prediction for an hour is the average occupancy at the same hour of the week over the last 8 weeks of assignment history, or at the same hour of the day while there is less than two weeks of history; the interval widens with the spread of those hours. Predictions are limited to the number of spaces that exist now.
+// @Tags Reports
+// @Produce json
+// @Param type query string false "Space type, e.g. icu; all spaces when omitted"
+// @Param horizon query string false "Forecast period in whole hours, at most 336h" default(72h)
+// @Success 200 {object} OccupancyForecast "Hourly occupancy forecast"
+// @Failure 400 {object} problem.Problem "Bad request - invalid horizon or less than two days of history"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/forecast/occupancy [get]
+func (s *SpaceServiceImpl) GetOccupancyForecast(c *gin.Context) {
+	horizon := defaultForecastHorizon
+	if value := c.Query("horizon"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < time.Hour || parsed > maxForecastHorizon || parsed%time.Hour != 0 {
+			problem.Write(c, problem.InvalidQuery("horizon", "must be whole hours between 1h and 336h"))
+			return
+		}
+		horizon = parsed
+	}
+
+	var spaceType *string
+	if value := c.Query("type"); value != "" {
+		spaceType = &value
+	}
+
+	ctx, cancel := s.dbService.AggregateContext(c.Request.Context())
+	defer cancel()
+
+	// The current hour is not complete, it is forecast rather than observed
+	to := time.Now().UTC().Truncate(time.Hour)
+	from, err := s.historyStart(ctx, to)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to read assignment history"))
+		return
+	}
+	history, err := s.hourlyOccupancy(ctx, spaceType, from, to)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to read assignment history"))
+		return
+	}
+
+	filter := notDeleted(bson.M{})
+	if spaceType != nil {
+		filter["type"] = *spaceType
+	}
+	spaces, err := s.dbService.GetCollection(collectionSpaces).CountDocuments(ctx, filter)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to count spaces"))
+		return
+	}
+
+	season, period := SeasonWeekly, 7*24
+	if len(history) < 2*period {
+		season, period = SeasonDaily, 24
+	}
+	predictions, err := forecast.SeasonalAverage(history, period, int(horizon/time.Hour), forecast.Z95)
+	if err == forecast.ErrInsufficientHistory {
+		problem.Write(c, problem.Validation("insufficient_history",
+			fmt.Sprintf("At least 48 hours of assignment history are needed, there are %d", len(history))))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to forecast occupancy"))
+		return
+	}
+
+	result := &OccupancyForecast{
+		Type:         spaceType,
+		Spaces:       int(spaces),
+		Model:        "seasonal_average",
+		Season:       season,
+		HistoryFrom:  from,
+		HistoryHours: len(history),
+		Confidence:   0.95,
+		Points:       make([]ForecastPoint, len(predictions)),
+	}
+	for i, prediction := range predictions {
+		prediction = prediction.Clamp(0, float64(spaces))
+		result.Points[i] = ForecastPoint{
+			At:        to.Add(time.Duration(i) * time.Hour),
+			Predicted: roundHundredths(prediction.Value),
+			Lower:     roundHundredths(prediction.Lower),
+			Upper:     roundHundredths(prediction.Upper),
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// historyStart returns the first full hour of assignment history within the
+// forecast lookback before to, so that hours before the history was recorded
+// are not taken for empty ones. The history is recorded from when its migration
+// was applied; the periods it backfilled start earlier, at the last change of a
+// space, and would count the hours before as observed.
+func (s *SpaceServiceImpl) historyStart(ctx context.Context, to time.Time) (time.Time, error) {
+	from := to.Add(-forecastLookback)
+
+	appliedAt, err := migrations.AppliedAt(ctx, s.dbService, migrations.VersionAssignmentHistory)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if appliedAt == nil {
+		return to, nil
+	}
+
+	start := appliedAt.UTC().Truncate(time.Hour)
+	if start.Before(*appliedAt) {
+		start = start.Add(time.Hour)
+	}
+	if start.After(from) {
+		from = start
+	}
+	if from.After(to) {
+		from = to
+	}
+	return from, nil
+}
+
+// hourlyOccupancy returns the average number of occupied spaces during every hour
+// of [from, to), of one space type or of all spaces
+func (s *SpaceServiceImpl) hourlyOccupancy(ctx context.Context, spaceType *string, from, to time.Time) ([]float64, error) {
+	hours := make([]float64, int(to.Sub(from)/time.Hour))
+	if len(hours) == 0 {
+		return hours, nil
+	}
+
+	filter := bson.M{
+		"started_at": bson.M{"$lt": to},
+		"$or":        bson.A{bson.M{"ended_at": nil}, bson.M{"ended_at": bson.M{"$gt": from}}},
+	}
+	if spaceType != nil {
+		filter["space_type"] = *spaceType
+	}
+	cursor, err := s.dbService.GetCollection(collectionAssignmentHistory).Find(ctx, filter,
+		options.Find().SetProjection(bson.M{"started_at": 1, "ended_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var period AssignmentPeriod
+		if err := cursor.Decode(&period); err != nil {
+			return nil, err
+		}
+
+		// Spread the occupied time of the period over the hours it overlaps
+		start, end := period.StartedAt, to
+		if start.Before(from) {
+			start = from
+		}
+		if period.EndedAt != nil && period.EndedAt.Before(end) {
+			end = *period.EndedAt
+		}
+		for start.Before(end) {
+			hour := int(start.Sub(from) / time.Hour)
+			next := from.Add(time.Duration(hour+1) * time.Hour)
+			if next.After(end) {
+				next = end
+			}
+			hours[hour] += next.Sub(start).Hours()
+			start = next
+		}
+	}
+	return hours, cursor.Err()
+}
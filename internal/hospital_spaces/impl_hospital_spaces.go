@@ -1,97 +1,170 @@
 package hospital_spaces
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/fieldcrypt"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 const (
-	collectionSpaces     = "spaces"
-	collectionAmbulances = "ambulances"
-	ErrNoDocuments       = "no documents found"
+	collectionSpaces               = "spaces"
+	collectionAmbulances           = "ambulances"
+	collectionBuildings            = "buildings"
+	collectionFloors               = "floors"
+	collectionWings                = "wings"
+	collectionDepartments          = "departments"
+	collectionEquipment            = "equipment"
+	collectionEquipmentMovements   = "equipment_movements"
+	collectionPatients             = "patients"
+	collectionAssignmentHistory    = "assignment_history"
+	collectionAmbulanceRuns        = "ambulance_runs"
+	collectionSpaceSnapshots       = "space_snapshots"
+	collectionSpaceSnapshotEntries = "space_snapshot_entries"
+	ErrNoDocuments                 = "no documents found"
+)
+
+var (
+	errAssignedIDRequired   = errors.New("assigned_id is required for this assigned_type")
+	errAssignedTypeRequired = errors.New("assigned_type is required to assign a space")
+	errAssignedTypeInvalid  = errors.New("assigned_type must be patient, ambulance, equipment or department")
+	errDepartmentNotFound   = errors.New("Department not found")
+	// errSpaceChanged is returned when a space was changed or deleted between reading and updating it
+	errSpaceChanged = problem.Conflict("space_changed", "The space was changed or deleted concurrently, reload it and retry")
 )
 
 // SpaceServiceImpl implements the space service operations
 type SpaceServiceImpl struct {
 	dbService *db_service.DbService
+	spaces    *SpaceRepository
+	summary   summaryCache
 }
 
 // NewSpaceServiceImpl creates a new space service implementation
-func NewSpaceServiceImpl(dbService *db_service.DbService) *SpaceServiceImpl {
+func NewSpaceServiceImpl(dbService *db_service.DbService, keyring *fieldcrypt.Keyring) *SpaceServiceImpl {
 	return &SpaceServiceImpl{
 		dbService: dbService,
+		spaces:    NewSpaceRepository(dbService, keyring),
 	}
 }
 
 // CreateSpace creates a new hospital space
 // @Summary Create a new hospital space
-// @Description Create a new hospital space with the specified details
+// @Description Create a new hospital space with the specified details. When floor_id or wing_id is given the space is placed in the facility hierarchy and its floor number follows the referenced floor; floor is required otherwise.
 // @Tags Spaces
 // @Accept json
 // @Produce json
 // @Param space body SpaceCreateRequest true "Space creation details"
 // @Success 201 {object} Space "Space created successfully"
-// @Failure 400 {object} map[string]string "Bad request - invalid input"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input, floor or wing"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/spaces [post]
 func (s *SpaceServiceImpl) CreateSpace(c *gin.Context) {
 	var request SpaceCreateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+	if err := request.validateFloor(); err != nil {
+		problem.Write(c, err)
 		return
 	}
 
 	space := NewSpace(request)
-	collection := s.dbService.GetCollection(collectionSpaces)
-	ctx, cancel := s.dbService.CreateContext()
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
 	defer cancel()
 
-	result, err := collection.InsertOne(ctx, space)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create space: %v", err)})
+	// Reference the facility hierarchy when a floor or wing is given
+	if request.FloorID != nil || request.WingID != nil {
+		floor, wing, err := s.resolveLocation(ctx, request.FloorID, request.WingID)
+		if err != nil {
+			if isLocationError(err) {
+				problem.Write(c, invalidReference(err))
+				return
+			}
+			problem.Write(c, problem.Internal(err, "Failed to resolve location"))
+			return
+		}
+		space.SetLocation(floor, wing)
+	}
+
+	if err := s.spaces.Insert(ctx, space); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to create space"))
 		return
 	}
 
-	space.ID = result.InsertedID.(primitive.ObjectID)
 	c.JSON(http.StatusCreated, space)
 }
 
 // GetSpaces retrieves all hospital spaces
 // @Summary Get all hospital spaces
-// @Description Retrieve a list of all hospital spaces with their current status and assignments
+// @Description Retrieve a list of all hospital spaces with their current status and assignments. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles. Deleted spaces are only listed for admins with include_deleted=true. With as_of the spaces are listed as they were at that time; names and locations are the current ones and a space that stopped being occupied between snapshots is reported as available.
 // @Tags Spaces
 // @Accept json
 // @Produce json
+// @Param include_deleted query bool false "Also list deleted spaces that were not purged yet (admin role)"
+// @Param as_of query string false "List the spaces with their status and assignment at this time (RFC 3339), reconstructed from the nearest occupancy snapshot and the assignment history" format(date-time)
 // @Success 200 {array} Space "List of hospital spaces"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid query parameter or no snapshot kept for as_of"
+// @Failure 403 {object} problem.Problem "include_deleted requires the admin role"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/spaces [get]
 func (s *SpaceServiceImpl) GetSpaces(c *gin.Context) {
-	collection := s.dbService.GetCollection(collectionSpaces)
-	ctx, cancel := s.dbService.CreateContext()
-	defer cancel()
-
-	cursor, err := collection.Find(ctx, bson.M{})
+	withDeleted, err := includeDeleted(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve spaces: %v", err)})
+		problem.Write(c, err)
 		return
 	}
-	defer cursor.Close(ctx)
+
+	var asOf *time.Time
+	if value := c.Query("as_of"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			problem.Write(c, problem.InvalidQuery("as_of", "must be an RFC 3339 time"))
+			return
+		}
+		if parsed.After(time.Now()) {
+			problem.Write(c, problem.InvalidQuery("as_of", "must not be in the future"))
+			return
+		}
+		if withDeleted {
+			problem.Write(c, problem.InvalidQuery("as_of", "cannot be combined with include_deleted"))
+			return
+		}
+		asOf = &parsed
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
 
 	var spaces []Space
-	if err := cursor.All(ctx, &spaces); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to decode spaces: %v", err)})
+	if asOf != nil {
+		spaces, err = s.SpacesAsOf(ctx, *asOf)
+	} else {
+		spaces, err = s.spaces.Find(ctx, liveFilter(bson.M{}, withDeleted))
+	}
+	if err == errNoSnapshot {
+		problem.Write(c, problem.Validation("as_of_out_of_range", "No occupancy snapshot is kept for as_of, it is too far back or snapshots are disabled"))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve spaces"))
 		return
 	}
 
-	if spaces == nil {
-		spaces = []Space{}
+	if err := s.presentSpaces(ctx, c, spaces); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to resolve patient placements"))
+		return
 	}
 
 	c.JSON(http.StatusOK, spaces)
@@ -99,65 +172,78 @@ func (s *SpaceServiceImpl) GetSpaces(c *gin.Context) {
 
 // UpdateSpace updates a hospital space assignment
 // @Summary Update a hospital space
-// @Description Update space assignment details such as assigned entity, type, and ID
+// @Description Update space assignment details such as assigned entity, type, and ID. Every assignment has an assigned_type of patient, ambulance, equipment or department and references an existing entity by assigned_id; assigned_to is filled in from the entity, free text is rejected. Patient placements are shown with the MRN to the clinician and admin roles and with the pseudonym to other roles.
 // @Tags Spaces
 // @Accept json
 // @Produce json
 // @Param id path string true "The unique space ID (UUID format)" format(uuid)
 // @Param space body SpaceUpdateRequest true "Space update details"
 // @Success 200 {object} Space "Space updated successfully"
-// @Failure 400 {object} map[string]string "Bad request - invalid space ID or input"
-// @Failure 404 {object} map[string]string "Space not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid space ID, input or assigned entity"
+// @Failure 404 {object} problem.Problem "Space not found"
+// @Failure 409 {object} problem.Problem "The space was changed or deleted concurrently"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/spaces/{id} [put]
 func (s *SpaceServiceImpl) UpdateSpace(c *gin.Context) {
 	spaceIDStr := c.Param("id")
 	// Validate that it's a valid UUID format
 	if _, err := uuid.Parse(spaceIDStr); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid space ID"})
+		problem.Write(c, problem.Validation("invalid_space_id", "Invalid space ID"))
 		return
 	}
 
 	var request SpaceUpdateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, problem.InvalidBody(err))
 		return
 	}
 
-	collection := s.dbService.GetCollection(collectionSpaces)
-	ctx, cancel := s.dbService.CreateContext()
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
 	defer cancel()
 
-	// Find the space first
-	var space Space
-	filter := bson.M{"space_id": spaceIDStr}
-	err := collection.FindOne(ctx, filter).Decode(&space)
+	if err := s.validateAssignment(ctx, &request); err != nil {
+		if isAssignmentError(err) {
+			problem.Write(c, invalidReference(err))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to validate assignment"))
+		return
+	}
+
+	// Find the space first, deleted spaces have to be restored before they can be changed
+	filter := notDeleted(bson.M{"space_id": spaceIDStr})
+	space, err := s.spaces.FindOne(ctx, filter)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
+			problem.Write(c, problem.NotFound("space_not_found", "Space not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to find space: %v", err)})
+		problem.Write(c, problem.Internal(err, "Failed to find space"))
 		return
 	}
 
 	// Update the assignment
+	previous := *space
 	space.UpdateAssignment(request)
 
-	// Update in database with correct field names
-	update := bson.M{
-		"$set": bson.M{
-			"assigned_to":   space.AssignedTo,
-			"assigned_type": space.AssignedType,
-			"assigned_id":   space.AssignedID,
-			"status":        space.Status,
-			"updated_at":    space.UpdatedAt,
-		},
+	// Update in database, assigned_to is encrypted by the repository
+	updated, err := s.spaces.UpdateAssignment(ctx, space, previous.Version)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to update space"))
+		return
+	}
+	if !updated {
+		problem.Write(c, errSpaceChanged)
+		return
+	}
+
+	if err := s.spaces.RecordAssignment(ctx, previous, space); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to record assignment history"))
+		return
 	}
 
-	_, err = collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update space: %v", err)})
+	if err := s.presentSpace(ctx, c, space); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to resolve patient placement"))
 		return
 	}
 
@@ -166,41 +252,63 @@ func (s *SpaceServiceImpl) UpdateSpace(c *gin.Context) {
 
 // DeleteSpace deletes a hospital space
 // @Summary Delete a hospital space
-// @Description Remove a hospital space from the system
+// @Description Mark a hospital space as deleted. It keeps its assignment, is no longer listed and can be restored until it is purged after the retention period. A space where equipment is located cannot be deleted until the equipment is moved out.
 // @Tags Spaces
 // @Accept json
 // @Produce json
 // @Param id path string true "The unique space ID (UUID format)" format(uuid)
 // @Success 204 "Space deleted successfully"
-// @Failure 400 {object} map[string]string "Bad request - invalid space ID"
-// @Failure 404 {object} map[string]string "Space not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid space ID"
+// @Failure 404 {object} problem.Problem "Space not found"
+// @Failure 409 {object} problem.Problem "Equipment is located in the space"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/spaces/{id} [delete]
 func (s *SpaceServiceImpl) DeleteSpace(c *gin.Context) {
 	spaceIDStr := c.Param("id")
 	// Validate that it's a valid UUID format
 	if _, err := uuid.Parse(spaceIDStr); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid space ID"})
+		problem.Write(c, problem.Validation("invalid_space_id", "Invalid space ID"))
 		return
 	}
 
-	collection := s.dbService.GetCollection(collectionSpaces)
-	ctx, cancel := s.dbService.CreateContext()
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
 	defer cancel()
 
-	filter := bson.M{"space_id": spaceIDStr}
-	result, err := collection.DeleteOne(ctx, filter)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete space: %v", err)})
+	if err := s.deleteSpace(ctx, spaceIDStr); err != nil {
+		var conflict *problem.Error
+		switch {
+		case errors.Is(err, errSpaceNotFound):
+			problem.Write(c, problem.NotFound("space_not_found", "Space not found"))
+		case errors.As(err, &conflict):
+			problem.Write(c, conflict)
+		default:
+			problem.Write(c, problem.Internal(err, "Failed to delete space"))
+		}
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Space not found"})
-		return
+	c.Status(http.StatusNoContent)
+}
+
+// deleteSpace marks a space as deleted unless equipment is located there, which
+// would be left pointing at the tombstone
+func (s *SpaceServiceImpl) deleteSpace(ctx context.Context, spaceID string) error {
+	equipment, err := s.dbService.GetCollection(collectionEquipment).CountDocuments(ctx, bson.M{"space_id": spaceID})
+	if err != nil {
+		return err
+	}
+	if equipment > 0 {
+		return problem.Conflict("space_has_equipment", fmt.Sprintf("%d piece(s) of equipment are located in the space, move them out first", equipment))
 	}
 
-	c.Status(http.StatusNoContent)
+	deleted, err := s.spaces.SoftDelete(ctx, spaceID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errSpaceNotFound
+	}
+	return nil
 }
 
 // CreateAmbulance creates a new ambulance
@@ -211,24 +319,24 @@ func (s *SpaceServiceImpl) DeleteSpace(c *gin.Context) {
 // @Produce json
 // @Param ambulance body AmbulanceCreateRequest true "Ambulance creation details"
 // @Success 201 {object} Ambulance "Ambulance created successfully"
-// @Failure 400 {object} map[string]string "Bad request - invalid input"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/ambulances [post]
 func (s *SpaceServiceImpl) CreateAmbulance(c *gin.Context) {
 	var request AmbulanceCreateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, problem.InvalidBody(err))
 		return
 	}
 
 	ambulance := NewAmbulance(request)
 	collection := s.dbService.GetCollection(collectionAmbulances)
-	ctx, cancel := s.dbService.CreateContext()
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
 	defer cancel()
 
 	result, err := collection.InsertOne(ctx, ambulance)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create ambulance: %v", err)})
+		problem.Write(c, problem.Internal(err, "Failed to create ambulance"))
 		return
 	}
 
@@ -238,28 +346,37 @@ func (s *SpaceServiceImpl) CreateAmbulance(c *gin.Context) {
 
 // GetAmbulances retrieves all ambulances
 // @Summary Get all ambulances
-// @Description Retrieve a list of all ambulances in the system
+// @Description Retrieve a list of all ambulances in the system. Deleted ambulances are only listed for admins with include_deleted=true.
 // @Tags Ambulances
 // @Accept json
 // @Produce json
+// @Param include_deleted query bool false "Also list deleted ambulances that were not purged yet (admin role)"
 // @Success 200 {array} Ambulance "List of ambulances"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Bad request - invalid query parameter"
+// @Failure 403 {object} problem.Problem "include_deleted requires the admin role"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Router /api/ambulances [get]
 func (s *SpaceServiceImpl) GetAmbulances(c *gin.Context) {
+	withDeleted, err := includeDeleted(c)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
 	collection := s.dbService.GetCollection(collectionAmbulances)
-	ctx, cancel := s.dbService.CreateContext()
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
 	defer cancel()
 
-	cursor, err := collection.Find(ctx, bson.M{})
+	cursor, err := collection.Find(ctx, liveFilter(bson.M{}, withDeleted))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to retrieve ambulances: %v", err)})
+		problem.Write(c, problem.Internal(err, "Failed to retrieve ambulances"))
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var ambulances []Ambulance
 	if err := cursor.All(ctx, &ambulances); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to decode ambulances: %v", err)})
+		problem.Write(c, problem.Internal(err, "Failed to decode ambulances"))
 		return
 	}
 
@@ -269,3 +386,110 @@ func (s *SpaceServiceImpl) GetAmbulances(c *gin.Context) {
 
 	c.JSON(http.StatusOK, ambulances)
 }
+
+// validateAssignment checks that an assignment references an existing entity
+// and fills in the display name of referenced entities
+func (s *SpaceServiceImpl) validateAssignment(ctx context.Context, req *SpaceUpdateRequest) error {
+	if req.AssignedType == nil || *req.AssignedType == "" {
+		// Free text could name a patient, every assignment references an entity
+		if req.AssignedTo != nil && *req.AssignedTo != "" {
+			return errAssignedTypeRequired
+		}
+		return nil
+	}
+
+	switch *req.AssignedType {
+	case AssignedTypeAmbulance:
+		if req.AssignedID == nil || *req.AssignedID == "" {
+			return errAssignedIDRequired
+		}
+		var ambulance Ambulance
+		err := s.dbService.GetCollection(collectionAmbulances).FindOne(ctx, notDeleted(bson.M{"ambulance_id": *req.AssignedID})).Decode(&ambulance)
+		if err == mongo.ErrNoDocuments {
+			return errAmbulanceNotFound
+		}
+		if err != nil {
+			return err
+		}
+		req.AssignedTo = &ambulance.Name
+	case AssignedTypeDepartment:
+		if req.AssignedID == nil || *req.AssignedID == "" {
+			return errAssignedIDRequired
+		}
+		var department Department
+		err := s.dbService.GetCollection(collectionDepartments).FindOne(ctx, bson.M{"department_id": *req.AssignedID}).Decode(&department)
+		if err == mongo.ErrNoDocuments {
+			return errDepartmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		req.AssignedTo = &department.Name
+	case AssignedTypeEquipment:
+		if req.AssignedID == nil || *req.AssignedID == "" {
+			return errAssignedIDRequired
+		}
+		var equipment Equipment
+		err := s.dbService.GetCollection(collectionEquipment).FindOne(ctx, bson.M{"equipment_id": *req.AssignedID}).Decode(&equipment)
+		if err == mongo.ErrNoDocuments {
+			return errEquipmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		req.AssignedTo = &equipment.Name
+	case AssignedTypePatient:
+		// Patients are referenced by ID only, the stored display value is the pseudonym
+		if req.AssignedID == nil || *req.AssignedID == "" {
+			return errAssignedIDRequired
+		}
+		var patient Patient
+		err := s.dbService.GetCollection(collectionPatients).FindOne(ctx, bson.M{"patient_id": *req.AssignedID}).Decode(&patient)
+		if err == mongo.ErrNoDocuments {
+			return errPatientNotFound
+		}
+		if err != nil {
+			return err
+		}
+		req.AssignedTo = &patient.Pseudonym
+	default:
+		return errAssignedTypeInvalid
+	}
+
+	return nil
+}
+
+// isAssignmentError reports whether err is caused by an invalid assignment reference
+func isAssignmentError(err error) bool {
+	return errors.Is(err, errAssignedIDRequired) || errors.Is(err, errAssignedTypeRequired) || errors.Is(err, errAssignedTypeInvalid) ||
+		errors.Is(err, errDepartmentNotFound) || errors.Is(err, errEquipmentNotFound) || errors.Is(err, errPatientNotFound) ||
+		errors.Is(err, errAmbulanceNotFound)
+}
+
+// referenceCodes are the problem codes of the sentinel errors for invalid references
+var referenceCodes = []struct {
+	sentinel error
+	code     string
+}{
+	{errAssignedIDRequired, "assigned_id_required"},
+	{errAssignedTypeRequired, "assigned_type_required"},
+	{errAssignedTypeInvalid, "assigned_type_invalid"},
+	{errAmbulanceNotFound, "ambulance_not_found"},
+	{errDepartmentNotFound, "department_not_found"},
+	{errEquipmentNotFound, "equipment_not_found"},
+	{errPatientNotFound, "patient_not_found"},
+	{errSpaceNotFound, "space_not_found"},
+	{errFloorNotFound, "floor_not_found"},
+	{errWingNotFound, "wing_not_found"},
+	{errWingNotOnFloor, "wing_not_on_floor"},
+}
+
+// invalidReference reports a request that refers to a missing or mismatching resource
+func invalidReference(err error) *problem.Error {
+	for _, reference := range referenceCodes {
+		if errors.Is(err, reference.sentinel) {
+			return problem.Validation(reference.code, err.Error())
+		}
+	}
+	return problem.Validation("invalid_reference", err.Error())
+}
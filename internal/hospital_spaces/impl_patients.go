@@ -0,0 +1,274 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/auth"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errPatientNotFound = errors.New("Patient not found")
+
+// CreatePatient registers a patient reference
+// @Summary Register a patient reference
+// @Description Register a patient by the external MRN. A pseudonymous ID is generated for display to roles without access to patient identities. Requires the clinician or admin role.
+// @Tags Patients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param patient body PatientCreateRequest true "Patient reference details"
+// @Success 201 {object} Patient "Patient registered successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid input"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Failure 409 {object} problem.Problem "MRN already registered"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/patients [post]
+func (s *SpaceServiceImpl) CreatePatient(c *gin.Context) {
+	var request PatientCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, problem.InvalidBody(err))
+		return
+	}
+
+	patient, err := NewPatient(request)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to generate pseudonym"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	result, err := s.dbService.GetCollection(collectionPatients).InsertOne(ctx, patient)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			problem.Write(c, problem.Conflict("duplicate_mrn", "Patient with this MRN already exists"))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to create patient"))
+		return
+	}
+
+	patient.ID = result.InsertedID.(primitive.ObjectID)
+	c.JSON(http.StatusCreated, patient)
+}
+
+// GetPatients retrieves all patient references
+// @Summary Get all patient references
+// @Description Retrieve all patient references. The MRN is only returned to the clinician and admin roles, other roles receive the redacted form. Filtering by MRN requires the clinician or admin role.
+// @Tags Patients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param mrn query string false "Only return the patient with this MRN"
+// @Success 200 {array} Patient "List of patient references"
+// @Failure 403 {object} problem.Problem "Insufficient role to filter by MRN"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/patients [get]
+func (s *SpaceServiceImpl) GetPatients(c *gin.Context) {
+	authorized := auth.CanViewPatientIdentity(auth.FromContext(c).Role)
+
+	filter := bson.M{}
+	if mrn := c.Query("mrn"); mrn != "" {
+		if !authorized {
+			problem.Write(c, problem.Forbidden("insufficient_role", "Insufficient role"))
+			return
+		}
+		filter["mrn"] = mrn
+	}
+
+	collection := s.dbService.GetCollection(collectionPatients)
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "pseudonym", Value: 1}}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to retrieve patients"))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	patients := []Patient{}
+	if err := cursor.All(ctx, &patients); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to decode patients"))
+		return
+	}
+
+	if !authorized {
+		for i := range patients {
+			patients[i] = patients[i].Redact()
+		}
+	}
+
+	c.JSON(http.StatusOK, patients)
+}
+
+// GetPatient retrieves a single patient reference
+// @Summary Get a patient reference
+// @Description Retrieve a patient reference by its ID. The MRN is only returned to the clinician and admin roles.
+// @Tags Patients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The unique patient ID (UUID format)" format(uuid)
+// @Success 200 {object} Patient "Patient reference"
+// @Failure 400 {object} problem.Problem "Bad request - invalid patient ID"
+// @Failure 404 {object} problem.Problem "Patient not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/patients/{id} [get]
+func (s *SpaceServiceImpl) GetPatient(c *gin.Context) {
+	patientID := c.Param("id")
+	if _, err := uuid.Parse(patientID); err != nil {
+		problem.Write(c, problem.Validation("invalid_patient_id", "Invalid patient ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.ReadContext(c.Request.Context())
+	defer cancel()
+
+	var patient Patient
+	err := s.dbService.GetCollection(collectionPatients).FindOne(ctx, bson.M{"patient_id": patientID}).Decode(&patient)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			problem.Write(c, problem.NotFound("patient_not_found", errPatientNotFound.Error()))
+			return
+		}
+		problem.Write(c, problem.Internal(err, "Failed to find patient"))
+		return
+	}
+
+	if !auth.CanViewPatientIdentity(auth.FromContext(c).Role) {
+		patient = patient.Redact()
+	}
+
+	c.JSON(http.StatusOK, patient)
+}
+
+// DeletePatient deletes a patient reference
+// @Summary Delete a patient reference
+// @Description Remove a patient reference. Deletion is rejected while the patient is placed in a space. Requires the clinician or admin role.
+// @Tags Patients
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "The unique patient ID (UUID format)" format(uuid)
+// @Success 204 "Patient deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid patient ID"
+// @Failure 401 {object} problem.Problem "API key required"
+// @Failure 403 {object} problem.Problem "Insufficient role"
+// @Failure 404 {object} problem.Problem "Patient not found"
+// @Failure 409 {object} problem.Problem "Patient is still placed in a space"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/patients/{id} [delete]
+func (s *SpaceServiceImpl) DeletePatient(c *gin.Context) {
+	patientID := c.Param("id")
+	if _, err := uuid.Parse(patientID); err != nil {
+		problem.Write(c, problem.Validation("invalid_patient_id", "Invalid patient ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	placed, err := s.exists(ctx, collectionSpaces, notDeleted(bson.M{"assigned_type": AssignedTypePatient, "assigned_id": patientID}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to check patient placement"))
+		return
+	}
+	if placed {
+		problem.Write(c, problem.Conflict("patient_placed", "Patient is still placed in a space"))
+		return
+	}
+
+	result, err := s.dbService.GetCollection(collectionPatients).DeleteOne(ctx, bson.M{"patient_id": patientID})
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete patient"))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		problem.Write(c, problem.NotFound("patient_not_found", errPatientNotFound.Error()))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// presentSpaces resolves the patient placements of spaces for the caller. The clinician
+// and admin roles see the MRN of placed patients, other roles see the pseudonym, and
+// patient assignments that cannot be resolved are redacted for them.
+func (s *SpaceServiceImpl) presentSpaces(ctx context.Context, c *gin.Context, spaces []Space) error {
+	authorized := auth.CanViewPatientIdentity(auth.FromContext(c).Role)
+
+	var patientIDs []string
+	for _, space := range spaces {
+		if isPatientAssignment(space) && space.AssignedID != nil {
+			patientIDs = append(patientIDs, *space.AssignedID)
+		}
+	}
+
+	patients := map[string]Patient{}
+	if len(patientIDs) > 0 {
+		cursor, err := s.dbService.GetCollection(collectionPatients).Find(ctx, bson.M{"patient_id": bson.M{"$in": patientIDs}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		var found []Patient
+		if err := cursor.All(ctx, &found); err != nil {
+			return err
+		}
+		for _, patient := range found {
+			patients[patient.PatientID] = patient
+		}
+	}
+
+	for i := range spaces {
+		if !isPatientAssignment(spaces[i]) {
+			continue
+		}
+
+		var patient Patient
+		var ok bool
+		if spaces[i].AssignedID != nil {
+			patient, ok = patients[*spaces[i].AssignedID]
+		}
+
+		switch {
+		case ok && authorized:
+			spaces[i].AssignedTo = &patient.MRN
+		case ok:
+			spaces[i].AssignedTo = &patient.Pseudonym
+		case !authorized:
+			redacted := redactedAssignment
+			spaces[i].AssignedTo = &redacted
+		}
+	}
+
+	return nil
+}
+
+// presentSpace resolves the patient placement of a single space for the caller
+func (s *SpaceServiceImpl) presentSpace(ctx context.Context, c *gin.Context, space *Space) error {
+	spaces := []Space{*space}
+	if err := s.presentSpaces(ctx, c, spaces); err != nil {
+		return err
+	}
+	*space = spaces[0]
+	return nil
+}
+
+// isPatientAssignment reports whether a space is assigned to a patient
+func isPatientAssignment(space Space) bool {
+	return space.AssignedType != nil && *space.AssignedType == AssignedTypePatient
+}
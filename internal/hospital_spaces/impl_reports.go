@@ -0,0 +1,480 @@
+package hospital_spaces
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	// Reports group by hour in any timezone, the runtime image has no zoneinfo
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// defaultReportRange is the report period when from is not given
+	defaultReportRange = 30 * 24 * time.Hour
+	// maxReportRange bounds the report period
+	maxReportRange = 366 * 24 * time.Hour
+)
+
+// ambulanceReportPhases are the phases of an ambulance run in report order
+var ambulanceReportPhases = []string{"dispatch_to_en_route", "en_route_to_arrival", "turnaround"}
+
+// utilizationCSVHeader lists the columns of a utilization report in CSV
+var utilizationCSVHeader = []string{"key", "spaces", "assignments", "occupied_hours", "utilization_percent", "average_stay_hours", "peak_concurrent"}
+
+// GetUtilizationReport reports space utilization from the assignment history
+// @Summary Get the space utilization report
+// @Description Report the utilization of spaces between from and to, grouped by space type, floor number or department, computed from the assignment history. Occupied hours sum the assignment periods within the report period and utilization relates them to the hours the spaces of the group were available; spaces of a type or floor are those that exist now, spaces of a department are those assigned to it in the period. The average stay covers the assignments that ended in the period and the peak is the highest number of spaces of the group occupied at the same time. Type and floor are those of the space when an assignment started.
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the report period (RFC 3339), not in the future, defaults to 30 days before to" format(date-time)
+// @Param to query string false "End of the report period (RFC 3339), defaults to now" format(date-time)
+// @Param group_by query string false "Grouping of the spaces" Enums(type, floor, department) default(type)
+// @Param format query string false "Response format" Enums(json, csv) default(json)
+// @Success 200 {object} UtilizationReport "Utilization per group"
+// @Failure 400 {object} problem.Problem "Bad request - invalid period, grouping or format"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/reports/utilization [get]
+func (s *SpaceServiceImpl) GetUtilizationReport(c *gin.Context) {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", GroupByType)
+	if groupBy != GroupByType && groupBy != GroupByFloor && groupBy != GroupByDepartment {
+		problem.Write(c, problem.InvalidQuery("group_by", "must be type, floor or department"))
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != FormatCSV {
+		problem.Write(c, problem.InvalidQuery("format", "must be json or csv"))
+		return
+	}
+
+	ctx, cancel := s.dbService.AggregateContext(c.Request.Context())
+	defer cancel()
+
+	report, err := s.utilization(ctx, from, to, groupBy)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to compute utilization"))
+		return
+	}
+
+	if format == FormatCSV {
+		rows := make([][]string, 0, len(report.Groups))
+		for _, group := range report.Groups {
+			averageStay := ""
+			if group.AverageStayHours != nil {
+				averageStay = formatFigure(*group.AverageStayHours)
+			}
+			rows = append(rows, []string{
+				group.Key, strconv.Itoa(group.Spaces), strconv.Itoa(group.Assignments), formatFigure(group.OccupiedHours),
+				formatFigure(group.UtilizationPercent), averageStay, strconv.Itoa(group.PeakConcurrent),
+			})
+		}
+		writeReportCSV(c, "utilization", utilizationCSVHeader, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// utilization aggregates the assignment periods overlapping [from, to) per group
+func (s *SpaceServiceImpl) utilization(ctx context.Context, from, to time.Time, groupBy string) (*UtilizationReport, error) {
+	// Spaces cannot be occupied in the future, open periods end now
+	end := to
+	if now := time.Now(); now.Before(end) {
+		end = now
+	}
+
+	match := bson.M{
+		"started_at": bson.M{"$lt": end},
+		"$or":        bson.A{bson.M{"ended_at": nil}, bson.M{"ended_at": bson.M{"$gt": from}}},
+	}
+	key := map[string]string{GroupByType: "$space_type", GroupByFloor: "$floor", GroupByDepartment: "$department_id"}[groupBy]
+	if groupBy == GroupByDepartment {
+		match["department_id"] = bson.M{"$ne": nil}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		// Clip the periods to the report period
+		{{Key: "$project", Value: bson.M{
+			"key":        key,
+			"space_id":   1,
+			"started_at": 1,
+			"ended_at":   1,
+			"start":      bson.M{"$max": bson.A{"$started_at", from}},
+			"end":        bson.M{"$min": bson.A{bson.M{"$ifNull": bson.A{"$ended_at", end}}, end}},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":         "$key",
+					"assignments": bson.M{"$sum": 1},
+					"occupied_ms": bson.M{"$sum": bson.M{"$subtract": bson.A{"$end", "$start"}}},
+					// $avg skips the nulls of assignments that did not end in the period
+					"stay_ms": bson.M{"$avg": bson.M{"$cond": bson.A{
+						bson.M{"$and": bson.A{bson.M{"$gt": bson.A{"$ended_at", nil}}, bson.M{"$lte": bson.A{"$ended_at", end}}}},
+						bson.M{"$subtract": bson.A{"$ended_at", "$started_at"}},
+						nil,
+					}}},
+					"spaces": bson.M{"$addToSet": "$space_id"},
+				}},
+			},
+			// Sweep over the starts and ends, ends first so that back-to-back periods do not overlap
+			"peaks": bson.A{
+				bson.M{"$project": bson.M{"key": 1, "events": bson.A{
+					bson.M{"at": "$start", "delta": 1},
+					bson.M{"at": "$end", "delta": -1},
+				}}},
+				bson.M{"$unwind": "$events"},
+				bson.M{"$setWindowFields": bson.M{
+					"partitionBy": "$key",
+					"sortBy":      bson.D{{Key: "events.at", Value: 1}, {Key: "events.delta", Value: 1}},
+					"output": bson.M{"concurrent": bson.M{
+						"$sum":   "$events.delta",
+						"window": bson.M{"documents": bson.A{"unbounded", "current"}},
+					}},
+				}},
+				bson.M{"$group": bson.M{"_id": "$key", "peak": bson.M{"$max": "$concurrent"}}},
+			},
+		}}},
+	}
+
+	cursor, err := s.dbService.GetCollection(collectionAssignmentHistory).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Totals []struct {
+			Key         interface{} `bson:"_id"`
+			Assignments int         `bson:"assignments"`
+			OccupiedMs  int64       `bson:"occupied_ms"`
+			StayMs      *float64    `bson:"stay_ms"`
+			Spaces      []string    `bson:"spaces"`
+		} `bson:"totals"`
+		Peaks []struct {
+			Key  interface{} `bson:"_id"`
+			Peak int         `bson:"peak"`
+		} `bson:"peaks"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*UtilizationGroup{}
+	group := func(key interface{}) *UtilizationGroup {
+		name := fmt.Sprint(key)
+		if groups[name] == nil {
+			groups[name] = &UtilizationGroup{Key: name}
+		}
+		return groups[name]
+	}
+	if len(facets) > 0 {
+		for _, row := range facets[0].Totals {
+			g := group(row.Key)
+			g.Assignments = row.Assignments
+			g.OccupiedHours = float64(row.OccupiedMs) / float64(time.Hour/time.Millisecond)
+			if row.StayMs != nil {
+				hours := *row.StayMs / float64(time.Hour/time.Millisecond)
+				g.AverageStayHours = &hours
+			}
+			if groupBy == GroupByDepartment {
+				g.Spaces = len(row.Spaces)
+			}
+		}
+		for _, row := range facets[0].Peaks {
+			group(row.Key).PeakConcurrent = row.Peak
+		}
+	}
+
+	// Types and floors are measured against the spaces that exist now
+	if groupBy != GroupByDepartment {
+		counts, err := s.countSpaces(ctx, map[string]string{GroupByType: "$type", GroupByFloor: "$floor"}[groupBy])
+		if err != nil {
+			return nil, err
+		}
+		for key, count := range counts {
+			group(key).Spaces = count
+		}
+	}
+
+	periodHours := end.Sub(from).Hours()
+	report := &UtilizationReport{From: from, To: to, GroupBy: groupBy, Groups: make([]UtilizationGroup, 0, len(groups))}
+	for _, g := range groups {
+		if g.Spaces > 0 && periodHours > 0 {
+			g.UtilizationPercent = roundHundredths(g.OccupiedHours / (float64(g.Spaces) * periodHours) * 100)
+		}
+		g.OccupiedHours = roundHundredths(g.OccupiedHours)
+		if g.AverageStayHours != nil {
+			rounded := roundHundredths(*g.AverageStayHours)
+			g.AverageStayHours = &rounded
+		}
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return lessKey(report.Groups[i].Key, report.Groups[j].Key)
+	})
+	return report, nil
+}
+
+// countSpaces counts the live spaces per value of a field expression
+func (s *SpaceServiceImpl) countSpaces(ctx context.Context, field string) (map[string]int, error) {
+	cursor, err := s.dbService.GetCollection(collectionSpaces).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{})}},
+		{{Key: "$group", Value: bson.M{"_id": field, "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Key   interface{} `bson:"_id"`
+		Count int         `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[fmt.Sprint(row.Key)] = row.Count
+	}
+	return counts, nil
+}
+
+// GetAmbulanceReport reports ambulance response and turnaround times
+// @Summary Get the ambulance response time report
+// @Description Report the times from dispatch to en route, from en route to arrival and from arrival until the ambulance is available again (bay turnaround) of the runs dispatched between from and to, grouped by ambulance type or by the hour of the day the run was dispatched in the given timezone. Every phase has its count, mean and nearest-rank 50th, 90th and 95th percentiles in minutes; cancelled runs count towards the phases they completed.
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Start of the report period (RFC 3339), not in the future, defaults to 30 days before to" format(date-time)
+// @Param to query string false "End of the report period (RFC 3339), defaults to now" format(date-time)
+// @Param group_by query string false "Grouping of the runs" Enums(type, hour) default(type)
+// @Param timezone query string false "IANA timezone of the hours of the day" default(UTC)
+// @Param format query string false "Response format" Enums(json, csv) default(json)
+// @Success 200 {object} AmbulanceReport "Response and turnaround times per group"
+// @Failure 400 {object} problem.Problem "Bad request - invalid period, grouping, timezone or format"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/reports/ambulances [get]
+func (s *SpaceServiceImpl) GetAmbulanceReport(c *gin.Context) {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", GroupByType)
+	if groupBy != GroupByType && groupBy != GroupByHour {
+		problem.Write(c, problem.InvalidQuery("group_by", "must be type or hour"))
+		return
+	}
+
+	timezone := c.DefaultQuery("timezone", "UTC")
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+		problem.Write(c, problem.InvalidQuery("timezone", "must be an IANA timezone such as Europe/Bratislava"))
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != FormatCSV {
+		problem.Write(c, problem.InvalidQuery("format", "must be json or csv"))
+		return
+	}
+
+	ctx, cancel := s.dbService.AggregateContext(c.Request.Context())
+	defer cancel()
+
+	report, err := s.ambulanceReport(ctx, from, to, groupBy, timezone)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to compute ambulance report"))
+		return
+	}
+
+	if format == FormatCSV {
+		header := []string{"key", "runs", "cancelled"}
+		for _, phase := range ambulanceReportPhases {
+			for _, column := range []string{"count", "mean_minutes", "p50_minutes", "p90_minutes", "p95_minutes"} {
+				header = append(header, phase+"_"+column)
+			}
+		}
+		rows := make([][]string, 0, len(report.Groups))
+		for _, group := range report.Groups {
+			row := []string{group.Key, strconv.Itoa(group.Runs), strconv.Itoa(group.Cancelled)}
+			for _, stats := range []DurationStats{group.DispatchToEnRoute, group.EnRouteToArrival, group.Turnaround} {
+				row = append(row, strconv.Itoa(stats.Count))
+				for _, value := range []*float64{stats.MeanMinutes, stats.P50Minutes, stats.P90Minutes, stats.P95Minutes} {
+					if value == nil {
+						row = append(row, "")
+						continue
+					}
+					row = append(row, formatFigure(*value))
+				}
+			}
+			rows = append(rows, row)
+		}
+		writeReportCSV(c, "ambulances", header, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ambulanceReport aggregates the durations of the phases of the runs dispatched in [from, to) per group
+func (s *SpaceServiceImpl) ambulanceReport(ctx context.Context, from, to time.Time, groupBy, timezone string) (*AmbulanceReport, error) {
+	var key interface{} = "$ambulance_type"
+	if groupBy == GroupByHour {
+		key = bson.M{"$hour": bson.M{"date": "$dispatched_at", "timezone": timezone}}
+	}
+
+	// $subtract yields null for phases a run did not complete
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"dispatched_at": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":                  key,
+			"runs":                 bson.M{"$sum": 1},
+			"cancelled":            bson.M{"$sum": bson.M{"$cond": bson.A{"$cancelled", 1, 0}}},
+			"dispatch_to_en_route": bson.M{"$push": bson.M{"$subtract": bson.A{"$en_route_at", "$dispatched_at"}}},
+			"en_route_to_arrival":  bson.M{"$push": bson.M{"$subtract": bson.A{"$arrived_at", "$en_route_at"}}},
+			"turnaround":           bson.M{"$push": bson.M{"$subtract": bson.A{"$available_at", "$arrived_at"}}},
+		}}},
+	}
+
+	cursor, err := s.dbService.GetCollection(collectionAmbulanceRuns).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Key               interface{} `bson:"_id"`
+		Runs              int         `bson:"runs"`
+		Cancelled         int         `bson:"cancelled"`
+		DispatchToEnRoute []*int64    `bson:"dispatch_to_en_route"`
+		EnRouteToArrival  []*int64    `bson:"en_route_to_arrival"`
+		Turnaround        []*int64    `bson:"turnaround"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	report := &AmbulanceReport{From: from, To: to, GroupBy: groupBy, Timezone: timezone, Groups: make([]AmbulanceReportGroup, 0, len(rows))}
+	for _, row := range rows {
+		report.Groups = append(report.Groups, AmbulanceReportGroup{
+			Key:               fmt.Sprint(row.Key),
+			Runs:              row.Runs,
+			Cancelled:         row.Cancelled,
+			DispatchToEnRoute: NewDurationStats(completed(row.DispatchToEnRoute)),
+			EnRouteToArrival:  NewDurationStats(completed(row.EnRouteToArrival)),
+			Turnaround:        NewDurationStats(completed(row.Turnaround)),
+		})
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return lessKey(report.Groups[i].Key, report.Groups[j].Key)
+	})
+	return report, nil
+}
+
+// completed drops the durations of phases that were not completed
+func completed(durations []*int64) []int64 {
+	values := make([]int64, 0, len(durations))
+	for _, duration := range durations {
+		if duration != nil {
+			values = append(values, *duration)
+		}
+	}
+	return values
+}
+
+// reportPeriod reads the from and to query parameters of a report. to defaults
+// to now and from to defaultReportRange before to; a period starting in the
+// future has nothing to report.
+func reportPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	to := now
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, problem.InvalidQuery("to", "must be an RFC 3339 time")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultReportRange)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, problem.InvalidQuery("from", "must be an RFC 3339 time")
+		}
+		from = parsed
+	}
+
+	if from.After(now) {
+		return time.Time{}, time.Time{}, problem.InvalidQuery("from", "must not be in the future")
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, problem.InvalidQuery("from", "must be before to")
+	}
+	if to.Sub(from) > maxReportRange {
+		return time.Time{}, time.Time{}, problem.InvalidQuery("from", "the report period must not exceed 366 days")
+	}
+	return from, to, nil
+}
+
+// writeReportCSV responds with the rows of a report as a CSV attachment
+func writeReportCSV(c *gin.Context, name string, header []string, rows [][]string) {
+	c.Header("Content-Type", contentTypeCSV)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	writer, err := newExportWriter(c.Writer, FormatCSV, header)
+	for _, row := range rows {
+		if err != nil {
+			break
+		}
+		err = writer.write(nil, row)
+	}
+	if err == nil {
+		err = writer.flush()
+	}
+	if err != nil {
+		// The response is already streaming, the client sees a truncated body
+		_ = c.Error(err)
+	}
+}
+
+// lessKey orders group keys, numerically when both are numbers such as floors
+func lessKey(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
+
+// roundHundredths rounds a report figure to two decimals
+func roundHundredths(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// formatFigure formats a report figure for CSV, e.g. hours, minutes or a percentage, with two decimals
+func formatFigure(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,300 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/auth"
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errAmbulanceNotFound = errors.New("Ambulance not found")
+
+// RestoreSpace brings back a deleted hospital space
+// @Summary Restore a deleted hospital space
+// @Description Restore a space that was deleted and not yet purged, with its assignment. A space whose floor or wing was deleted in the meantime is restored outside the facility hierarchy, and a space whose assignee was deleted is restored available.
+// @Tags Spaces
+// @Produce json
+// @Param id path string true "The unique space ID (UUID format)" format(uuid)
+// @Success 200 {object} Space "Space restored successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid space ID"
+// @Failure 404 {object} problem.Problem "Space not found"
+// @Failure 409 {object} problem.Problem "Space is not deleted"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces/{id}/restore [post]
+func (s *SpaceServiceImpl) RestoreSpace(c *gin.Context) {
+	spaceIDStr := c.Param("id")
+	if _, err := uuid.Parse(spaceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_space_id", "Invalid space ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	space, err := s.spaces.FindOne(ctx, bson.M{"space_id": spaceIDStr, "deleted_at": bson.M{"$ne": nil}})
+	if err == mongo.ErrNoDocuments {
+		problem.Write(c, s.notDeletedOrMissing(ctx, collectionSpaces, bson.M{"space_id": spaceIDStr}, "space",
+			problem.NotFound("space_not_found", errSpaceNotFound.Error())))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find space"))
+		return
+	}
+
+	// The location may have been deleted while the space was, restoring it there would leave dangling references
+	update := bson.M{
+		"$set": bson.M{"deleted_at": nil, "updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if space.FloorID != nil {
+		_, _, err := s.resolveLocation(ctx, space.FloorID, space.WingID)
+		if isLocationError(err) {
+			update["$unset"] = bson.M{"building_id": "", "floor_id": "", "wing_id": ""}
+		} else if err != nil {
+			problem.Write(c, problem.Internal(err, "Failed to resolve location"))
+			return
+		}
+	}
+
+	// The assignee may have been deleted as well, the space is restored free then
+	if space.Status == "occupied" {
+		assignment := SpaceUpdateRequest{AssignedTo: space.AssignedTo, AssignedType: space.AssignedType, AssignedID: space.AssignedID}
+		err := s.validateAssignment(ctx, &assignment)
+		if isAssignmentError(err) {
+			set := update["$set"].(bson.M)
+			set["assigned_to"], set["assigned_type"], set["assigned_id"] = nil, nil, nil
+			set["status"] = "available"
+		} else if err != nil {
+			problem.Write(c, problem.Internal(err, "Failed to validate assignment"))
+			return
+		}
+	}
+
+	// Matching deleted_at guards against a concurrent restore or purge
+	filter := bson.M{"space_id": spaceIDStr, "deleted_at": space.DeletedAt}
+	result, err := s.dbService.GetCollection(collectionSpaces).UpdateOne(ctx, filter, update)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to restore space"))
+		return
+	}
+	if result.MatchedCount == 0 {
+		problem.Write(c, problem.Conflict("space_not_deleted", "The space was restored or purged concurrently"))
+		return
+	}
+
+	restored, err := s.spaces.FindOne(ctx, notDeleted(bson.M{"space_id": spaceIDStr}))
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to find space"))
+		return
+	}
+
+	// An occupied space counts as occupied again from now on
+	if err := s.spaces.RecordAssignment(ctx, Space{}, restored); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to record assignment history"))
+		return
+	}
+	if err := s.presentSpace(ctx, c, restored); err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to resolve patient placement"))
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// DeleteAmbulance deletes an ambulance
+// @Summary Delete an ambulance
+// @Description Mark an ambulance as deleted. It is no longer listed and can be restored until it is purged after the retention period.
+// @Tags Ambulances
+// @Param id path string true "The unique ambulance ID (UUID format)" format(uuid)
+// @Success 204 "Ambulance deleted successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid ambulance ID"
+// @Failure 404 {object} problem.Problem "Ambulance not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/ambulances/{id} [delete]
+func (s *SpaceServiceImpl) DeleteAmbulance(c *gin.Context) {
+	ambulanceIDStr := c.Param("id")
+	if _, err := uuid.Parse(ambulanceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_ambulance_id", "Invalid ambulance ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	now := time.Now()
+	result, err := s.dbService.GetCollection(collectionAmbulances).UpdateOne(ctx,
+		notDeleted(bson.M{"ambulance_id": ambulanceIDStr}),
+		bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to delete ambulance"))
+		return
+	}
+	if result.MatchedCount == 0 {
+		problem.Write(c, problem.NotFound("ambulance_not_found", errAmbulanceNotFound.Error()))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreAmbulance brings back a deleted ambulance
+// @Summary Restore a deleted ambulance
+// @Description Restore an ambulance that was deleted and not yet purged
+// @Tags Ambulances
+// @Produce json
+// @Param id path string true "The unique ambulance ID (UUID format)" format(uuid)
+// @Success 200 {object} Ambulance "Ambulance restored successfully"
+// @Failure 400 {object} problem.Problem "Bad request - invalid ambulance ID"
+// @Failure 404 {object} problem.Problem "Ambulance not found"
+// @Failure 409 {object} problem.Problem "Ambulance is not deleted"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/ambulances/{id}/restore [post]
+func (s *SpaceServiceImpl) RestoreAmbulance(c *gin.Context) {
+	ambulanceIDStr := c.Param("id")
+	if _, err := uuid.Parse(ambulanceIDStr); err != nil {
+		problem.Write(c, problem.Validation("invalid_ambulance_id", "Invalid ambulance ID"))
+		return
+	}
+
+	ctx, cancel := s.dbService.WriteContext(c.Request.Context())
+	defer cancel()
+
+	var ambulance Ambulance
+	err := s.dbService.GetCollection(collectionAmbulances).FindOneAndUpdate(ctx,
+		bson.M{"ambulance_id": ambulanceIDStr, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$set": bson.M{"deleted_at": nil, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&ambulance)
+	if err == mongo.ErrNoDocuments {
+		problem.Write(c, s.notDeletedOrMissing(ctx, collectionAmbulances, bson.M{"ambulance_id": ambulanceIDStr}, "ambulance",
+			problem.NotFound("ambulance_not_found", errAmbulanceNotFound.Error())))
+		return
+	}
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to restore ambulance"))
+		return
+	}
+
+	c.JSON(http.StatusOK, ambulance)
+}
+
+// notDeletedOrMissing reports why a record to restore was not found among the
+// tombstones: it is live, or it does not exist (anymore) and notFound is returned
+func (s *SpaceServiceImpl) notDeletedOrMissing(ctx context.Context, collectionName string, filter bson.M, resource string, notFound *problem.Error) error {
+	live, err := s.exists(ctx, collectionName, notDeleted(filter))
+	if err != nil {
+		return problem.Internal(err, "Failed to find "+resource)
+	}
+	if live {
+		return problem.Conflict(resource+"_not_deleted", "The "+resource+" is not deleted")
+	}
+	return notFound
+}
+
+// notDeleted restricts a filter to live records. Records written before soft
+// deletes have no deleted_at field, which matches null as well.
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// liveFilter returns the filter for list endpoints, which include tombstones only when asked to
+func liveFilter(filter bson.M, includeDeleted bool) bson.M {
+	if includeDeleted {
+		return filter
+	}
+	return notDeleted(filter)
+}
+
+// includeDeleted reads the include_deleted query parameter, which only admins may set
+func includeDeleted(c *gin.Context) (bool, error) {
+	value := c.Query("include_deleted")
+	if value == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, problem.InvalidQuery("include_deleted", "must be true or false")
+	}
+	if include && auth.FromContext(c).Role != auth.RoleAdmin {
+		return false, problem.Forbidden("include_deleted_forbidden", "Only admins can list deleted records")
+	}
+	return include, nil
+}
+
+// PurgeResult reports the tombstones removed by a purge
+type PurgeResult struct {
+	DryRun     bool      `json:"dry_run"`
+	Cutoff     time.Time `json:"cutoff"`
+	Spaces     int64     `json:"spaces"`
+	Ambulances int64     `json:"ambulances"`
+}
+
+// PurgeDeleted permanently removes the spaces and ambulances deleted before
+// cutoff. With dryRun they are only counted.
+func PurgeDeleted(ctx context.Context, dbService *db_service.DbService, cutoff time.Time, dryRun bool) (*PurgeResult, error) {
+	result := &PurgeResult{DryRun: dryRun, Cutoff: cutoff}
+	filter := bson.M{"deleted_at": bson.M{"$lt": cutoff}}
+
+	for _, target := range []struct {
+		collection string
+		count      *int64
+	}{
+		{collectionSpaces, &result.Spaces},
+		{collectionAmbulances, &result.Ambulances},
+	} {
+		collection := dbService.GetCollection(target.collection)
+		if dryRun {
+			count, err := collection.CountDocuments(ctx, filter)
+			if err != nil {
+				return result, err
+			}
+			*target.count = count
+			continue
+		}
+		deleted, err := collection.DeleteMany(ctx, filter)
+		if err != nil {
+			return result, err
+		}
+		*target.count = deleted.DeletedCount
+	}
+	return result, nil
+}
+
+// RunPurge purges the tombstones older than retention immediately and then every
+// interval until ctx is cancelled
+func RunPurge(ctx context.Context, dbService *db_service.DbService, retention, interval time.Duration, logger zerolog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeCtx, cancel := dbService.WriteContext(ctx)
+		result, err := PurgeDeleted(purgeCtx, dbService, time.Now().Add(-retention), false)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Warn().Err(err).Msg("Failed to purge deleted spaces and ambulances")
+		case err == nil && result.Spaces+result.Ambulances > 0:
+			logger.Info().Int64("spaces", result.Spaces).Int64("ambulances", result.Ambulances).Msg("Purged deleted spaces and ambulances")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package hospital_spaces
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"golang.org/x/sync/singleflight"
+)
+
+// summaryTTL is how long a space summary is reused before it is computed again
+const summaryTTL = 5 * time.Second
+
+// summaryCache keeps the last space summary, so that dashboards polling the
+// summary do not each run the aggregation
+type summaryCache struct {
+	mu        sync.Mutex
+	summary   *SpaceSummary
+	expiresAt time.Time
+	// refresh runs one aggregation at a time for all callers of an expired cache
+	refresh singleflight.Group
+}
+
+// GetSpaceSummary returns the live occupancy of all spaces
+// @Summary Get the occupancy summary of all spaces
+// @Description Count the spaces by status and sum their total and occupied capacity, in total and broken down by space type and by floor number. Deleted spaces are not counted. The summary is computed at most every few seconds, generated_at tells when.
+// @Tags Spaces
+// @Produce json
+// @Success 200 {object} SpaceSummary "Occupancy summary"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/spaces/summary [get]
+func (s *SpaceServiceImpl) GetSpaceSummary(c *gin.Context) {
+	summary, expiresAt, err := s.cachedSummary(c.Request.Context())
+	if err != nil {
+		problem.Write(c, problem.Internal(err, "Failed to summarize spaces"))
+		return
+	}
+
+	maxAge := int(math.Ceil(time.Until(expiresAt).Seconds()))
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", max(maxAge, 0)))
+	c.JSON(http.StatusOK, summary)
+}
+
+// cachedSummary returns the cached summary or computes it when the cache expired.
+// Concurrent callers share a single aggregation and stop waiting for it when
+// their own context ends.
+func (s *SpaceServiceImpl) cachedSummary(ctx context.Context) (*SpaceSummary, time.Time, error) {
+	s.summary.mu.Lock()
+	summary, expiresAt := s.summary.summary, s.summary.expiresAt
+	s.summary.mu.Unlock()
+	if summary != nil && time.Now().Before(expiresAt) {
+		return summary, expiresAt, nil
+	}
+
+	results := s.summary.refresh.DoChan("summary", func() (interface{}, error) {
+		// The result is shared with other callers, so it must not depend on this caller going away
+		ctx, cancel := s.dbService.AggregateContext(context.WithoutCancel(ctx))
+		defer cancel()
+
+		summary, err := s.spaces.Summary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.summary.mu.Lock()
+		defer s.summary.mu.Unlock()
+		s.summary.summary = summary
+		s.summary.expiresAt = summary.GeneratedAt.Add(summaryTTL)
+		return summary, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, time.Time{}, result.Err
+		}
+		summary := result.Val.(*SpaceSummary)
+		return summary, summary.GeneratedAt.Add(summaryTTL), nil
+	}
+}
@@ -0,0 +1,199 @@
+package hospital_spaces
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportChunkSize is the number of documents read and written at a time during an export
+const exportChunkSize = 200
+
+// spaceCSVRequired lists the columns a space CSV import must have; floor, floor_id
+// and wing_id are optional, but a row needs a floor or a floor_id or wing_id
+var spaceCSVRequired = []string{"name", "type", "capacity"}
+
+// spaceCSVHeader lists the columns of a space CSV export. It includes the import
+// columns, so that an export can be imported into another environment.
+var spaceCSVHeader = []string{
+	"space_id", "name", "type", "floor", "capacity", "status",
+	"building_id", "floor_id", "wing_id", "assigned_type", "assigned_id", "assigned_to",
+	"version", "created_at", "updated_at",
+}
+
+// ambulanceCSVRequired lists the columns an ambulance CSV import must have
+var ambulanceCSVRequired = []string{"name", "type", "location"}
+
+// ambulanceCSVHeader lists the columns of an ambulance CSV export
+var ambulanceCSVHeader = []string{"ambulance_id", "name", "type", "location", "status", "created_at", "updated_at"}
+
+// LoadSpaces creates a space for each row of a CSV or NDJSON file. Rows are
+// validated like the create space request and inserted in unordered batches;
+// with dryRun the rows are only validated. The returned error reports an
+// unreadable file or a database failure, invalid rows are reported in the result.
+func (s *SpaceServiceImpl) LoadSpaces(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{DryRun: dryRun}
+	inserter := newBatchInserter(result, func(ctx context.Context, spaces []*Space) error {
+		ctx, cancel := s.dbService.WriteContext(ctx)
+		defer cancel()
+		return s.spaces.InsertMany(ctx, spaces)
+	})
+
+	err := readImportRows(r, format, spaceCSVRequired, spaceRequestFromCSV, func(line int, request SpaceCreateRequest, rowErr *problem.Error) error {
+		if rowErr != nil {
+			result.reject(line, rowErr)
+			return nil
+		}
+		if rowErr := request.validateFloor(); rowErr != nil {
+			result.reject(line, rowErr)
+			return nil
+		}
+
+		space := NewSpace(request)
+		if request.FloorID != nil || request.WingID != nil {
+			ctx, cancel := s.dbService.ReadContext(ctx)
+			floor, wing, err := s.resolveLocation(ctx, request.FloorID, request.WingID)
+			cancel()
+			if isLocationError(err) {
+				result.reject(line, invalidReference(err))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			space.SetLocation(floor, wing)
+		}
+		return inserter.add(ctx, line, space)
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, inserter.finish(ctx)
+}
+
+// LoadAmbulances creates an ambulance for each row of a CSV or NDJSON file, see LoadSpaces
+func (s *SpaceServiceImpl) LoadAmbulances(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{DryRun: dryRun}
+	inserter := newBatchInserter(result, func(ctx context.Context, ambulances []*Ambulance) error {
+		ctx, cancel := s.dbService.WriteContext(ctx)
+		defer cancel()
+		docs := make([]interface{}, len(ambulances))
+		for i, ambulance := range ambulances {
+			docs[i] = ambulance
+		}
+		_, err := s.dbService.GetCollection(collectionAmbulances).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+		return err
+	})
+
+	err := readImportRows(r, format, ambulanceCSVRequired, ambulanceRequestFromCSV, func(line int, request AmbulanceCreateRequest, rowErr *problem.Error) error {
+		if rowErr != nil {
+			result.reject(line, rowErr)
+			return nil
+		}
+		return inserter.add(ctx, line, NewAmbulance(request))
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, inserter.finish(ctx)
+}
+
+// DumpSpaces writes all spaces to w as CSV or NDJSON, streaming them from the
+// cursor. present adjusts each chunk before it is written, e.g. to hide patient
+// identities from the caller.
+func (s *SpaceServiceImpl) DumpSpaces(ctx context.Context, w io.Writer, format string, present func(context.Context, []Space) error) error {
+	writer, err := newExportWriter(w, format, spaceCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	err = s.spaces.Stream(ctx, notDeleted(bson.M{}), exportChunkSize, func(spaces []Space) error {
+		if err := present(ctx, spaces); err != nil {
+			return err
+		}
+		for _, space := range spaces {
+			if err := writer.write(space, space.csvRecord()); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+	if err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+// DumpAmbulances writes all ambulances to w as CSV or NDJSON, streaming them from the cursor
+func (s *SpaceServiceImpl) DumpAmbulances(ctx context.Context, w io.Writer, format string) error {
+	writer, err := newExportWriter(w, format, ambulanceCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := s.dbService.GetCollection(collectionAmbulances).Find(ctx, notDeleted(bson.M{}), options.Find().SetBatchSize(exportChunkSize))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var ambulance Ambulance
+		if err := cursor.Decode(&ambulance); err != nil {
+			return err
+		}
+		if err := writer.write(ambulance, ambulance.csvRecord()); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+// spaceRequestFromCSV maps a CSV record to a create space request
+func spaceRequestFromCSV(field csvField) (SpaceCreateRequest, *problem.Error) {
+	var fields []problem.FieldError
+	request := SpaceCreateRequest{
+		Name:     field("name"),
+		Type:     field("type"),
+		Floor:    csvOptionalInt(field, "floor", &fields),
+		Capacity: csvInt(field, "capacity", &fields),
+		FloorID:  csvOptional(field, "floor_id"),
+		WingID:   csvOptional(field, "wing_id"),
+	}
+	if len(fields) > 0 {
+		return request, problem.Validation("validation_failed", "The row contains invalid fields", fields...)
+	}
+	return request, nil
+}
+
+// ambulanceRequestFromCSV maps a CSV record to a create ambulance request
+func ambulanceRequestFromCSV(field csvField) (AmbulanceCreateRequest, *problem.Error) {
+	return AmbulanceCreateRequest{
+		Name:     field("name"),
+		Type:     field("type"),
+		Location: field("location"),
+	}, nil
+}
+
+// csvRecord returns the space as a row of spaceCSVHeader
+func (s Space) csvRecord() []string {
+	return []string{
+		s.SpaceID, s.Name, s.Type, strconv.Itoa(s.Floor), strconv.Itoa(s.Capacity), s.Status,
+		optionalString(s.BuildingID), optionalString(s.FloorID), optionalString(s.WingID),
+		optionalString(s.AssignedType), optionalString(s.AssignedID), optionalString(s.AssignedTo),
+		strconv.FormatInt(s.Version, 10), s.CreatedAt.Format(time.RFC3339), s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvRecord returns the ambulance as a row of ambulanceCSVHeader
+func (a Ambulance) csvRecord() []string {
+	return []string{a.AmbulanceID, a.Name, a.Type, a.Location, a.Status, a.CreatedAt.Format(time.RFC3339), a.UpdatedAt.Format(time.RFC3339)}
+}
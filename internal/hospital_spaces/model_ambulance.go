@@ -7,6 +7,25 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Statuses of an ambulance
+const (
+	AmbulanceStatusAvailable   = "available"
+	AmbulanceStatusDispatched  = "dispatched"
+	AmbulanceStatusEnRoute     = "en_route"
+	AmbulanceStatusArrived     = "arrived"
+	AmbulanceStatusMaintenance = "maintenance"
+)
+
+// ambulanceTransitions lists the statuses an ambulance can change to from each
+// status. Returning to available before arrival cancels the run.
+var ambulanceTransitions = map[string][]string{
+	AmbulanceStatusAvailable:   {AmbulanceStatusDispatched, AmbulanceStatusMaintenance},
+	AmbulanceStatusDispatched:  {AmbulanceStatusEnRoute, AmbulanceStatusAvailable},
+	AmbulanceStatusEnRoute:     {AmbulanceStatusArrived, AmbulanceStatusAvailable},
+	AmbulanceStatusArrived:     {AmbulanceStatusAvailable},
+	AmbulanceStatusMaintenance: {AmbulanceStatusAvailable},
+}
+
 // Ambulance represents an ambulance in the system
 type Ambulance struct {
 	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
@@ -15,8 +34,12 @@ type Ambulance struct {
 	Location    string             `json:"location" bson:"location" binding:"required"`
 	Status      string             `json:"status" bson:"status"`
 	Type        string             `json:"type" bson:"type" binding:"required"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	// StatusChangedAt is when the ambulance changed to its current status
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" bson:"status_changed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" bson:"updated_at"`
+	// DeletedAt marks a deleted ambulance that can still be restored, see Space.DeletedAt
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at"`
 }
 
 // AmbulanceCreateRequest represents the request for creating a new ambulance
@@ -26,16 +49,46 @@ type AmbulanceCreateRequest struct {
 	Location string `json:"location" bson:"location" binding:"required"`
 }
 
+// AmbulanceStatusRequest represents the request for changing the status of an ambulance
+type AmbulanceStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=available dispatched en_route arrived maintenance"`
+}
+
+// AmbulanceRun is one dispatch of an ambulance with the times of its status
+// transitions, from dispatch until the ambulance is available again. A run that
+// ended before arrival is cancelled; an open run has no available_at.
+type AmbulanceRun struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	AmbulanceID   string             `json:"ambulance_id" bson:"ambulance_id"`
+	AmbulanceType string             `json:"ambulance_type" bson:"ambulance_type"`
+	DispatchedAt  time.Time          `json:"dispatched_at" bson:"dispatched_at"`
+	EnRouteAt     *time.Time         `json:"en_route_at" bson:"en_route_at"`
+	ArrivedAt     *time.Time         `json:"arrived_at" bson:"arrived_at"`
+	AvailableAt   *time.Time         `json:"available_at" bson:"available_at"`
+	Cancelled     bool               `json:"cancelled" bson:"cancelled"`
+}
+
+// CanTransition reports whether the ambulance can change to the given status
+func (a *Ambulance) CanTransition(status string) bool {
+	for _, next := range ambulanceTransitions[a.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
 // NewAmbulance creates a new Ambulance with default values
 func NewAmbulance(req AmbulanceCreateRequest) *Ambulance {
 	now := time.Now()
 	return &Ambulance{
-		AmbulanceID: uuid.New().String(),
-		Name:        req.Name,
-		Type:        req.Type,
-		Location:    req.Location,
-		Status:      "available",
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		AmbulanceID:     uuid.New().String(),
+		Name:            req.Name,
+		Type:            req.Type,
+		Location:        req.Location,
+		Status:          AmbulanceStatusAvailable,
+		StatusChangedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 }
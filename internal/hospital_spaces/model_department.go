@@ -0,0 +1,57 @@
+package hospital_spaces
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Department represents a hospital department owning spaces.
+// The spaces owned by a department are those assigned to it with assigned_type=department.
+type Department struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	DepartmentID string             `json:"department_id" bson:"department_id"`
+	Name         string             `json:"name" bson:"name"`
+	Code         string             `json:"code" bson:"code"`
+	Head         *DepartmentHead    `json:"head,omitempty" bson:"head,omitempty"`
+	Contact      DepartmentContact  `json:"contact" bson:"contact"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// DepartmentHead represents the head of a department
+type DepartmentHead struct {
+	Name  string `json:"name" bson:"name" binding:"required"`
+	Email string `json:"email,omitempty" bson:"email,omitempty" binding:"omitempty,email"`
+	Phone string `json:"phone,omitempty" bson:"phone,omitempty"`
+}
+
+// DepartmentContact represents the contact information of a department
+type DepartmentContact struct {
+	Email    string `json:"email,omitempty" bson:"email,omitempty" binding:"omitempty,email"`
+	Phone    string `json:"phone,omitempty" bson:"phone,omitempty"`
+	Location string `json:"location,omitempty" bson:"location,omitempty"`
+}
+
+// DepartmentRequest represents the request for creating or updating a department
+type DepartmentRequest struct {
+	Name    string            `json:"name" bson:"name" binding:"required"`
+	Code    string            `json:"code" bson:"code" binding:"required"`
+	Head    *DepartmentHead   `json:"head,omitempty" bson:"head,omitempty"`
+	Contact DepartmentContact `json:"contact" bson:"contact"`
+}
+
+// NewDepartment creates a new Department with default values
+func NewDepartment(req DepartmentRequest) *Department {
+	now := time.Now()
+	return &Department{
+		DepartmentID: uuid.New().String(),
+		Name:         req.Name,
+		Code:         req.Code,
+		Head:         req.Head,
+		Contact:      req.Contact,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
@@ -0,0 +1,87 @@
+package hospital_spaces
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Equipment represents a piece of medical equipment such as a ventilator, monitor or bed
+type Equipment struct {
+	ID               primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	EquipmentID      string             `json:"equipment_id" bson:"equipment_id"`
+	Name             string             `json:"name" bson:"name"`
+	SerialNumber     string             `json:"serial_number" bson:"serial_number"`
+	Category         string             `json:"category" bson:"category"`
+	Status           string             `json:"status" bson:"status"`
+	MaintenanceDueAt *time.Time         `json:"maintenance_due_at,omitempty" bson:"maintenance_due_at,omitempty"`
+	SpaceID          *string            `json:"space_id,omitempty" bson:"space_id,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// EquipmentCreateRequest represents the request for registering new equipment
+type EquipmentCreateRequest struct {
+	Name             string     `json:"name" bson:"name" binding:"required"`
+	SerialNumber     string     `json:"serial_number" bson:"serial_number" binding:"required"`
+	Category         string     `json:"category" bson:"category" binding:"required,oneof=ventilator monitor bed infusion_pump defibrillator wheelchair other"`
+	MaintenanceDueAt *time.Time `json:"maintenance_due_at,omitempty" bson:"maintenance_due_at,omitempty"`
+	SpaceID          *string    `json:"space_id,omitempty" bson:"space_id,omitempty" binding:"omitempty,uuid"`
+}
+
+// EquipmentUpdateRequest represents the request for updating equipment details
+type EquipmentUpdateRequest struct {
+	Name             string     `json:"name" bson:"name" binding:"required"`
+	SerialNumber     string     `json:"serial_number" bson:"serial_number" binding:"required"`
+	Category         string     `json:"category" bson:"category" binding:"required,oneof=ventilator monitor bed infusion_pump defibrillator wheelchair other"`
+	Status           string     `json:"status" bson:"status" binding:"required,oneof=available in_use maintenance retired"`
+	MaintenanceDueAt *time.Time `json:"maintenance_due_at,omitempty" bson:"maintenance_due_at,omitempty"`
+}
+
+// EquipmentMoveRequest represents the request for moving equipment to another space.
+// A missing space_id moves the equipment out of any space, e.g. into storage.
+type EquipmentMoveRequest struct {
+	SpaceID *string `json:"space_id,omitempty" bson:"space_id,omitempty" binding:"omitempty,uuid"`
+	Reason  string  `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// EquipmentMovement records a single move of equipment between spaces
+type EquipmentMovement struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	EquipmentID string             `json:"equipment_id" bson:"equipment_id"`
+	FromSpaceID *string            `json:"from_space_id,omitempty" bson:"from_space_id,omitempty"`
+	ToSpaceID   *string            `json:"to_space_id,omitempty" bson:"to_space_id,omitempty"`
+	Reason      string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	MovedAt     time.Time          `json:"moved_at" bson:"moved_at"`
+}
+
+// NewEquipment creates new Equipment with default values
+func NewEquipment(req EquipmentCreateRequest) *Equipment {
+	now := time.Now()
+	return &Equipment{
+		EquipmentID:      uuid.New().String(),
+		Name:             req.Name,
+		SerialNumber:     req.SerialNumber,
+		Category:         req.Category,
+		Status:           "available",
+		MaintenanceDueAt: req.MaintenanceDueAt,
+		SpaceID:          req.SpaceID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// MoveTo moves the equipment to the given space and returns the movement record
+func (e *Equipment) MoveTo(spaceID *string, reason string) *EquipmentMovement {
+	movement := &EquipmentMovement{
+		EquipmentID: e.EquipmentID,
+		FromSpaceID: e.SpaceID,
+		ToSpaceID:   spaceID,
+		Reason:      reason,
+		MovedAt:     time.Now(),
+	}
+	e.SpaceID = spaceID
+	e.UpdatedAt = movement.MovedAt
+	return movement
+}
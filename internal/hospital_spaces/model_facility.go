@@ -0,0 +1,161 @@
+package hospital_spaces
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Building represents a hospital building
+type Building struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	BuildingID string             `json:"building_id" bson:"building_id"`
+	Name       string             `json:"name" bson:"name"`
+	Code       string             `json:"code" bson:"code"`
+	Address    string             `json:"address,omitempty" bson:"address,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// BuildingRequest represents the request for creating or updating a building
+type BuildingRequest struct {
+	Name    string `json:"name" bson:"name" binding:"required"`
+	Code    string `json:"code" bson:"code" binding:"required"`
+	Address string `json:"address,omitempty" bson:"address,omitempty"`
+}
+
+// Floor represents a floor within a building
+type Floor struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	FloorID    string             `json:"floor_id" bson:"floor_id"`
+	BuildingID string             `json:"building_id" bson:"building_id"`
+	Level      int                `json:"level" bson:"level"`
+	Name       string             `json:"name" bson:"name"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// FloorRequest represents the request for creating or updating a floor
+type FloorRequest struct {
+	BuildingID string `json:"building_id" bson:"building_id" binding:"required,uuid"`
+	Level      int    `json:"level" bson:"level"`
+	Name       string `json:"name" bson:"name" binding:"required"`
+}
+
+// Wing represents a wing of a floor
+type Wing struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	WingID    string             `json:"wing_id" bson:"wing_id"`
+	FloorID   string             `json:"floor_id" bson:"floor_id"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WingRequest represents the request for creating or updating a wing
+type WingRequest struct {
+	FloorID string `json:"floor_id" bson:"floor_id" binding:"required,uuid"`
+	Name    string `json:"name" bson:"name" binding:"required"`
+}
+
+// Occupancy holds aggregated space occupancy for a node of the facility tree
+type Occupancy struct {
+	TotalSpaces       int     `json:"total_spaces"`
+	OccupiedSpaces    int     `json:"occupied_spaces"`
+	AvailableSpaces   int     `json:"available_spaces"`
+	MaintenanceSpaces int     `json:"maintenance_spaces"`
+	TotalCapacity     int     `json:"total_capacity"`
+	OccupiedCapacity  int     `json:"occupied_capacity"`
+	OccupancyRate     float64 `json:"occupancy_rate"`
+}
+
+// WingNode is a wing with its occupancy in the facility tree
+type WingNode struct {
+	Wing
+	Occupancy Occupancy `json:"occupancy"`
+}
+
+// FloorNode is a floor with its wings and occupancy in the facility tree
+type FloorNode struct {
+	Floor
+	Occupancy Occupancy  `json:"occupancy"`
+	Wings     []WingNode `json:"wings"`
+}
+
+// BuildingNode is a building with its floors and occupancy in the facility tree
+type BuildingNode struct {
+	Building
+	Occupancy Occupancy   `json:"occupancy"`
+	Floors    []FloorNode `json:"floors"`
+}
+
+// NewBuilding creates a new Building with default values
+func NewBuilding(req BuildingRequest) *Building {
+	now := time.Now()
+	return &Building{
+		BuildingID: uuid.New().String(),
+		Name:       req.Name,
+		Code:       req.Code,
+		Address:    req.Address,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NewFloor creates a new Floor with default values
+func NewFloor(req FloorRequest) *Floor {
+	now := time.Now()
+	return &Floor{
+		FloorID:    uuid.New().String(),
+		BuildingID: req.BuildingID,
+		Level:      req.Level,
+		Name:       req.Name,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NewWing creates a new Wing with default values
+func NewWing(req WingRequest) *Wing {
+	now := time.Now()
+	return &Wing{
+		WingID:    uuid.New().String(),
+		FloorID:   req.FloorID,
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// statusOccupancy is the occupancy of spaces that all have the given status
+func statusOccupancy(status string, spaces, capacity int) Occupancy {
+	occupancy := Occupancy{TotalSpaces: spaces, TotalCapacity: capacity}
+	switch status {
+	case "occupied":
+		occupancy.OccupiedSpaces = spaces
+		occupancy.OccupiedCapacity = capacity
+	case "maintenance":
+		occupancy.MaintenanceSpaces = spaces
+	default:
+		occupancy.AvailableSpaces = spaces
+	}
+	return occupancy
+}
+
+// add accumulates the counts of another occupancy into this one
+func (o *Occupancy) add(other Occupancy) {
+	o.TotalSpaces += other.TotalSpaces
+	o.OccupiedSpaces += other.OccupiedSpaces
+	o.AvailableSpaces += other.AvailableSpaces
+	o.MaintenanceSpaces += other.MaintenanceSpaces
+	o.TotalCapacity += other.TotalCapacity
+	o.OccupiedCapacity += other.OccupiedCapacity
+}
+
+// computeRate sets the occupancy rate from the space counts
+func (o *Occupancy) computeRate() {
+	if o.TotalSpaces > 0 {
+		o.OccupancyRate = float64(o.OccupiedSpaces) / float64(o.TotalSpaces)
+	}
+}
@@ -0,0 +1,36 @@
+package hospital_spaces
+
+import "time"
+
+// Seasons of the occupancy forecast
+const (
+	// SeasonWeekly averages the same hour of the week, used from two weeks of history
+	SeasonWeekly = "weekly"
+	// SeasonDaily averages the same hour of the day, used from two days of history
+	SeasonDaily = "daily"
+)
+
+// ForecastPoint is the predicted number of occupied spaces during an hour, with
+// the bounds of its prediction interval
+type ForecastPoint struct {
+	At        time.Time `json:"at"`
+	Predicted float64   `json:"predicted"`
+	Lower     float64   `json:"lower"`
+	Upper     float64   `json:"upper"`
+}
+
+// OccupancyForecast is the hourly forecast of occupied spaces
+type OccupancyForecast struct {
+	// Type is the space type forecast, all spaces when it is not set
+	Type *string `json:"type,omitempty"`
+	// Spaces is the number of spaces that exist now, predictions do not exceed it
+	Spaces int    `json:"spaces"`
+	Model  string `json:"model"`
+	Season string `json:"season"`
+	// HistoryFrom is the start of the hourly occupancy the forecast is computed from
+	HistoryFrom  time.Time `json:"history_from"`
+	HistoryHours int       `json:"history_hours"`
+	// Confidence is the coverage of the prediction intervals
+	Confidence float64         `json:"confidence"`
+	Points     []ForecastPoint `json:"points"`
+}
@@ -0,0 +1,64 @@
+package hospital_spaces
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// redactedAssignment replaces patient identities that cannot be resolved for the caller
+const redactedAssignment = "[redacted]"
+
+// Patient is a minimal reference to a patient of the hospital information system.
+// It holds only the external medical record number (MRN) and a pseudonymous ID
+// that is safe to show to any role.
+type Patient struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	PatientID string             `json:"patient_id" bson:"patient_id"`
+	MRN       string             `json:"mrn,omitempty" bson:"mrn"`
+	Pseudonym string             `json:"pseudonym" bson:"pseudonym"`
+	Redacted  bool               `json:"redacted,omitempty" bson:"-"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// PatientCreateRequest represents the request for registering a patient reference
+type PatientCreateRequest struct {
+	MRN string `json:"mrn" bson:"mrn" binding:"required"`
+}
+
+// NewPatient creates a new Patient with a random pseudonym
+func NewPatient(req PatientCreateRequest) (*Patient, error) {
+	pseudonym, err := generatePseudonym()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Patient{
+		PatientID: uuid.New().String(),
+		MRN:       req.MRN,
+		Pseudonym: pseudonym,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Redact returns a copy of the patient without identifying data
+func (p Patient) Redact() Patient {
+	p.MRN = ""
+	p.Redacted = true
+	return p
+}
+
+// generatePseudonym returns a random pseudonymous patient identifier such as PT-1A2B3C4D5E6F
+func generatePseudonym() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "PT-" + strings.ToUpper(hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,141 @@
+package hospital_spaces
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Groupings of the utilization report
+const (
+	GroupByType       = "type"
+	GroupByFloor      = "floor"
+	GroupByDepartment = "department"
+	GroupByHour       = "hour"
+)
+
+// AssignmentPeriod is a period during which a space was occupied by one assignee.
+// It keeps the type and location the space had when the period started, an open
+// period has no end.
+type AssignmentPeriod struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	SpaceID      string             `json:"space_id" bson:"space_id"`
+	SpaceType    string             `json:"space_type" bson:"space_type"`
+	Floor        int                `json:"floor" bson:"floor"`
+	BuildingID   *string            `json:"building_id,omitempty" bson:"building_id,omitempty"`
+	FloorID      *string            `json:"floor_id,omitempty" bson:"floor_id,omitempty"`
+	AssignedType *string            `json:"assigned_type,omitempty" bson:"assigned_type,omitempty"`
+	AssignedID   *string            `json:"assigned_id,omitempty" bson:"assigned_id,omitempty"`
+	// DepartmentID is set for periods during which the space was assigned to a department
+	DepartmentID *string    `json:"department_id,omitempty" bson:"department_id,omitempty"`
+	StartedAt    time.Time  `json:"started_at" bson:"started_at"`
+	EndedAt      *time.Time `json:"ended_at" bson:"ended_at"`
+}
+
+// UtilizationGroup is the utilization of the spaces of a type, floor or department
+type UtilizationGroup struct {
+	// Key is the space type, the floor number or the department ID
+	Key string `json:"key"`
+	// Spaces is the number of spaces utilization is measured against
+	Spaces int `json:"spaces"`
+	// Assignments is the number of assignment periods overlapping the report period
+	Assignments        int     `json:"assignments"`
+	OccupiedHours      float64 `json:"occupied_hours"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+	// AverageStayHours is the mean length of the assignments that ended in the report period
+	AverageStayHours *float64 `json:"average_stay_hours"`
+	// PeakConcurrent is the highest number of spaces of the group occupied at the same time
+	PeakConcurrent int `json:"peak_concurrent"`
+}
+
+// UtilizationReport is the space utilization over a period
+type UtilizationReport struct {
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	GroupBy string             `json:"group_by"`
+	Groups  []UtilizationGroup `json:"groups"`
+}
+
+// DurationStats summarizes the durations of one phase of ambulance runs in minutes.
+// The figures are null when no run of the group completed the phase.
+type DurationStats struct {
+	Count       int      `json:"count"`
+	MeanMinutes *float64 `json:"mean_minutes"`
+	P50Minutes  *float64 `json:"p50_minutes"`
+	P90Minutes  *float64 `json:"p90_minutes"`
+	P95Minutes  *float64 `json:"p95_minutes"`
+}
+
+// AmbulanceReportGroup is the response and turnaround times of the runs of an
+// ambulance type or of the runs dispatched in an hour of the day
+type AmbulanceReportGroup struct {
+	// Key is the ambulance type or the hour of the day (0-23)
+	Key       string `json:"key"`
+	Runs      int    `json:"runs"`
+	Cancelled int    `json:"cancelled"`
+	// DispatchToEnRoute is the time from dispatch until the ambulance was en route
+	DispatchToEnRoute DurationStats `json:"dispatch_to_en_route"`
+	// EnRouteToArrival is the time from en route until arrival
+	EnRouteToArrival DurationStats `json:"en_route_to_arrival"`
+	// Turnaround is the time from arrival until the ambulance was available again
+	Turnaround DurationStats `json:"turnaround"`
+}
+
+// AmbulanceReport is the response and turnaround times of the ambulance runs
+// dispatched in a period
+type AmbulanceReport struct {
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	GroupBy  string                 `json:"group_by"`
+	Timezone string                 `json:"timezone"`
+	Groups   []AmbulanceReportGroup `json:"groups"`
+}
+
+// NewDurationStats computes the mean and the nearest-rank percentiles of durations
+// given in milliseconds
+func NewDurationStats(durations []int64) DurationStats {
+	stats := DurationStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	minutes := make([]float64, len(durations))
+	sum := 0.0
+	for i, duration := range durations {
+		minutes[i] = float64(duration) / float64(time.Minute/time.Millisecond)
+		sum += minutes[i]
+	}
+	sort.Float64s(minutes)
+
+	percentile := func(p float64) *float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(minutes))))
+		value := roundHundredths(minutes[max(rank, 1)-1])
+		return &value
+	}
+	mean := roundHundredths(sum / float64(len(minutes)))
+	stats.MeanMinutes = &mean
+	stats.P50Minutes = percentile(50)
+	stats.P90Minutes = percentile(90)
+	stats.P95Minutes = percentile(95)
+	return stats
+}
+
+// NewAssignmentPeriod opens the assignment period of an occupied space
+func NewAssignmentPeriod(space *Space) *AssignmentPeriod {
+	period := &AssignmentPeriod{
+		SpaceID:      space.SpaceID,
+		SpaceType:    space.Type,
+		Floor:        space.Floor,
+		BuildingID:   space.BuildingID,
+		FloorID:      space.FloorID,
+		AssignedType: space.AssignedType,
+		AssignedID:   space.AssignedID,
+		StartedAt:    space.UpdatedAt,
+	}
+	if space.AssignedType != nil && *space.AssignedType == AssignedTypeDepartment {
+		period.DepartmentID = space.AssignedID
+	}
+	return period
+}
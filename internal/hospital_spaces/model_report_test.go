@@ -0,0 +1,80 @@
+package hospital_spaces
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDurationStats(t *testing.T) {
+	minutes := func(values ...int64) []int64 {
+		durations := make([]int64, len(values))
+		for i, value := range values {
+			durations[i] = value * int64(time.Minute/time.Millisecond)
+		}
+		return durations
+	}
+
+	tests := []struct {
+		name                string
+		durations           []int64
+		count               int
+		mean, p50, p90, p95 float64
+	}{
+		{
+			name:      "single duration",
+			durations: minutes(7),
+			count:     1,
+			mean:      7, p50: 7, p90: 7, p95: 7,
+		},
+		{
+			// Nearest rank ⌈p/100·n⌉ of 1..10: 5, 9 and 10
+			name:      "nearest rank of ten",
+			durations: minutes(10, 9, 8, 7, 6, 5, 4, 3, 2, 1),
+			count:     10,
+			mean:      5.5, p50: 5, p90: 9, p95: 10,
+		},
+		{
+			// Ranks 2, 4 and 4 of four, no interpolation between them
+			name:      "nearest rank of four",
+			durations: minutes(1, 2, 3, 100),
+			count:     4,
+			mean:      26.5, p50: 2, p90: 100, p95: 100,
+		},
+		{
+			name:      "milliseconds to rounded minutes",
+			durations: []int64{90_000, 100_000},
+			count:     2,
+			mean:      1.58, p50: 1.5, p90: 1.67, p95: 1.67,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := NewDurationStats(tt.durations)
+			if stats.Count != tt.count {
+				t.Errorf("expected count %d, got %d", tt.count, stats.Count)
+			}
+			for _, figure := range []struct {
+				name string
+				got  *float64
+				want float64
+			}{
+				{"mean", stats.MeanMinutes, tt.mean},
+				{"p50", stats.P50Minutes, tt.p50},
+				{"p90", stats.P90Minutes, tt.p90},
+				{"p95", stats.P95Minutes, tt.p95},
+			} {
+				if figure.got == nil || *figure.got != figure.want {
+					t.Errorf("expected %s %v, got %v", figure.name, figure.want, figure.got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDurationStatsEmpty(t *testing.T) {
+	stats := NewDurationStats(nil)
+	if stats.Count != 0 || stats.MeanMinutes != nil || stats.P50Minutes != nil ||
+		stats.P90Minutes != nil || stats.P95Minutes != nil {
+		t.Errorf("expected zero count and null figures, got %+v", stats)
+	}
+}
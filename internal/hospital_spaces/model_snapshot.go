@@ -0,0 +1,44 @@
+package hospital_spaces
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SpaceSnapshot is the occupancy state of all live spaces at a point in time.
+// The state of every space is stored as a SnapshotSpace of its own, so that a
+// snapshot is not bound by the document size limit; the snapshot itself is
+// written once all of them are.
+type SpaceSnapshot struct {
+	ID      primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TakenAt time.Time          `json:"taken_at" bson:"taken_at"`
+	// Spaces is the number of spaces in the snapshot
+	Spaces int `json:"spaces" bson:"spaces"`
+}
+
+// SnapshotSpace is the compact state of a space in a snapshot. The assigned_to
+// display value is not kept, it may identify a patient.
+type SnapshotSpace struct {
+	SnapshotID   primitive.ObjectID `json:"-" bson:"snapshot_id,omitempty"`
+	TakenAt      time.Time          `json:"-" bson:"taken_at,omitempty"`
+	SpaceID      string             `json:"space_id" bson:"space_id"`
+	Status       string             `json:"status" bson:"status"`
+	AssignedType *string            `json:"assigned_type,omitempty" bson:"assigned_type,omitempty"`
+	AssignedID   *string            `json:"assigned_id,omitempty" bson:"assigned_id,omitempty"`
+}
+
+// apply sets the status and assignment of a space to the state. The current
+// assigned_to is only kept for the same referenced assignment, free text is not
+// kept in the state and may have changed since.
+func (state SnapshotSpace) apply(space *Space) {
+	sameAssignment := state.Status == "occupied" &&
+		state.AssignedType != nil && state.AssignedID != nil &&
+		equalRef(space.AssignedType, state.AssignedType) && equalRef(space.AssignedID, state.AssignedID)
+	if !sameAssignment {
+		space.AssignedTo = nil
+	}
+	space.Status = state.Status
+	space.AssignedType = state.AssignedType
+	space.AssignedID = state.AssignedID
+}
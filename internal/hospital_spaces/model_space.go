@@ -4,9 +4,18 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Assignment types of a space
+const (
+	AssignedTypePatient    = "patient"
+	AssignedTypeAmbulance  = "ambulance"
+	AssignedTypeEquipment  = "equipment"
+	AssignedTypeDepartment = "department"
+)
+
 // Space represents a hospital space/room
 type Space struct {
 	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
@@ -14,43 +23,158 @@ type Space struct {
 	Name         string             `json:"name" bson:"name" binding:"required"`
 	Type         string             `json:"type" bson:"type" binding:"required"`
 	Floor        int                `json:"floor" bson:"floor" binding:"required"`
+	BuildingID   *string            `json:"building_id,omitempty" bson:"building_id,omitempty"`
+	FloorID      *string            `json:"floor_id,omitempty" bson:"floor_id,omitempty"`
+	WingID       *string            `json:"wing_id,omitempty" bson:"wing_id,omitempty"`
 	Capacity     int                `json:"capacity" bson:"capacity" binding:"required"`
 	Status       string             `json:"status" bson:"status"`
 	AssignedTo   *string            `json:"assigned_to,omitempty" bson:"assigned_to,omitempty"`
 	AssignedType *string            `json:"assigned_type,omitempty" bson:"assigned_type,omitempty"`
 	AssignedID   *string            `json:"assigned_id,omitempty" bson:"assigned_id,omitempty"`
-	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+	// Version is incremented on every change of the space
+	Version   int64     `json:"version" bson:"version"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// DeletedAt marks a deleted space that can still be restored, it is stored as
+	// null for live spaces so that the partial unique indexes cover them
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at"`
 }
 
 // SpaceCreateRequest represents the request for creating a new space
 type SpaceCreateRequest struct {
-	Name     string `json:"name" bson:"name" binding:"required"`
-	Type     string `json:"type" bson:"type" binding:"required"`
-	Floor    int    `json:"floor" bson:"floor" binding:"required"`
-	Capacity int    `json:"capacity" bson:"capacity" binding:"required"`
+	Name string `json:"name" bson:"name" binding:"required"`
+	Type string `json:"type" bson:"type" binding:"required"`
+	// Floor is the floor number, required unless floor_id or wing_id places the space
+	Floor    *int    `json:"floor,omitempty" bson:"floor,omitempty"`
+	Capacity int     `json:"capacity" bson:"capacity" binding:"required"`
+	FloorID  *string `json:"floor_id,omitempty" bson:"floor_id,omitempty" binding:"omitempty,uuid"`
+	WingID   *string `json:"wing_id,omitempty" bson:"wing_id,omitempty" binding:"omitempty,uuid"`
+}
+
+// validateFloor requires the floor number of a space that is not placed in the
+// facility hierarchy, whose floor follows the referenced floor otherwise
+func (r SpaceCreateRequest) validateFloor() *problem.Error {
+	if r.Floor == nil && r.FloorID == nil && r.WingID == nil {
+		return problem.Validation("validation_failed", "The request body contains invalid fields",
+			problem.FieldError{Field: "floor", Message: "is required unless floor_id or wing_id is given"})
+	}
+	return nil
+}
+
+// SpaceLocationRequest represents the request for moving a space within the facility hierarchy
+type SpaceLocationRequest struct {
+	FloorID string  `json:"floor_id" bson:"floor_id" binding:"required,uuid"`
+	WingID  *string `json:"wing_id,omitempty" bson:"wing_id,omitempty" binding:"omitempty,uuid"`
 }
 
 // SpaceUpdateRequest represents the request for updating a space
 type SpaceUpdateRequest struct {
 	AssignedTo   *string `json:"assigned_to,omitempty" bson:"assigned_to,omitempty"`
-	AssignedType *string `json:"assigned_type,omitempty" bson:"assigned_type,omitempty"`
+	AssignedType *string `json:"assigned_type,omitempty" bson:"assigned_type,omitempty" binding:"omitempty,oneof=patient ambulance equipment department"`
 	AssignedID   *string `json:"assigned_id,omitempty" bson:"assigned_id,omitempty"`
 }
 
+// Operations of a space batch
+const (
+	BatchOpAssign      = "assign"
+	BatchOpRelease     = "release"
+	BatchOpMaintenance = "maintenance"
+	BatchOpDelete      = "delete"
+)
+
+// Modes of a space batch
+const (
+	// BatchModeAtomic applies all operations in a transaction or none of them
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort applies each operation on its own
+	BatchModeBestEffort = "best_effort"
+)
+
+// SpaceBatchOperation is an operation of a space batch. The assignment fields are
+// those of SpaceUpdateRequest and only used by assign operations.
+type SpaceBatchOperation struct {
+	Op           string  `json:"op" binding:"required,oneof=assign release maintenance delete"`
+	SpaceID      string  `json:"space_id" binding:"required,uuid"`
+	AssignedTo   *string `json:"assigned_to,omitempty"`
+	AssignedType *string `json:"assigned_type,omitempty" binding:"omitempty,oneof=patient ambulance equipment department"`
+	AssignedID   *string `json:"assigned_id,omitempty"`
+}
+
+// SpaceBatchRequest represents the request for applying operations to many spaces
+type SpaceBatchRequest struct {
+	Mode       string                `json:"mode,omitempty" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []SpaceBatchOperation `json:"operations" binding:"required,min=1,max=200,dive"`
+}
+
+// SpaceBatchError describes why an operation of a batch was not applied
+type SpaceBatchError struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Fields  []problem.FieldError `json:"fields,omitempty"`
+}
+
+// SpaceBatchResult is the outcome of an operation of a batch. Status is the HTTP
+// status the single-item endpoint would have responded with.
+type SpaceBatchResult struct {
+	Index   int              `json:"index"`
+	Op      string           `json:"op"`
+	SpaceID string           `json:"space_id"`
+	Status  int              `json:"status"`
+	Space   *Space           `json:"space,omitempty"`
+	Error   *SpaceBatchError `json:"error,omitempty"`
+}
+
+// SpaceBatchResponse reports the outcome of every operation of a batch in request order
+type SpaceBatchResponse struct {
+	Mode      string             `json:"mode"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []SpaceBatchResult `json:"results"`
+}
+
+// FloorOccupancy is the occupancy of the spaces on a floor number of a building.
+// Spaces outside the facility hierarchy are reported without a building.
+type FloorOccupancy struct {
+	BuildingID *string   `json:"building_id,omitempty"`
+	Floor      int       `json:"floor"`
+	Occupancy  Occupancy `json:"occupancy"`
+}
+
+// TypeOccupancy is the occupancy of the spaces of a type
+type TypeOccupancy struct {
+	Type      string    `json:"type"`
+	Occupancy Occupancy `json:"occupancy"`
+}
+
+// SpaceSummary is the occupancy of all live spaces, in total and broken down by
+// type and by building and floor
+type SpaceSummary struct {
+	Total  Occupancy       `json:"total"`
+	ByType []TypeOccupancy `json:"by_type"`
+	// ByFloor is ordered by building and floor number, the spaces outside the
+	// facility hierarchy come first, without a building
+	ByFloor []FloorOccupancy `json:"by_floor"`
+	// GeneratedAt is when the summary was computed, it may be cached for a few seconds
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
 // NewSpace creates a new Space with default values
 func NewSpace(req SpaceCreateRequest) *Space {
 	now := time.Now()
-	return &Space{
+	space := &Space{
 		SpaceID:   uuid.New().String(),
 		Name:      req.Name,
 		Type:      req.Type,
-		Floor:     req.Floor,
 		Capacity:  req.Capacity,
 		Status:    "available",
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if req.Floor != nil {
+		space.Floor = *req.Floor
+	}
+	return space
 }
 
 // UpdateAssignment updates the space assignment
@@ -66,5 +190,29 @@ func (s *Space) UpdateAssignment(req SpaceUpdateRequest) {
 		s.AssignedID = nil
 		s.Status = "available"
 	}
+	s.Version++
+	s.UpdatedAt = time.Now()
+}
+
+// SetMaintenance takes the space out of service, releasing its assignment
+func (s *Space) SetMaintenance() {
+	s.AssignedTo = nil
+	s.AssignedType = nil
+	s.AssignedID = nil
+	s.Status = "maintenance"
+	s.Version++
 	s.UpdatedAt = time.Now()
 }
+
+// SetLocation places the space on the given floor and optional wing. It does not
+// count as a change of the space, a new space keeps its first version.
+func (s *Space) SetLocation(floor *Floor, wing *Wing) {
+	s.BuildingID = &floor.BuildingID
+	s.FloorID = &floor.FloorID
+	s.Floor = floor.Level
+	if wing != nil {
+		s.WingID = &wing.WingID
+	} else {
+		s.WingID = nil
+	}
+}
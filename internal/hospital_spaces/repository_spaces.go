@@ -0,0 +1,473 @@
+package hospital_spaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/fieldcrypt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SpaceRepository reads and writes spaces. The assigned_to field may identify a
+// patient, so it is encrypted before it is stored and decrypted when it is read.
+type SpaceRepository struct {
+	dbService *db_service.DbService
+	keyring   *fieldcrypt.Keyring
+}
+
+// NewSpaceRepository creates a new space repository. A nil keyring stores assigned_to in plaintext.
+func NewSpaceRepository(dbService *db_service.DbService, keyring *fieldcrypt.Keyring) *SpaceRepository {
+	return &SpaceRepository{
+		dbService: dbService,
+		keyring:   keyring,
+	}
+}
+
+// Find returns all spaces matching the filter
+func (r *SpaceRepository) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]Space, error) {
+	cursor, err := r.collection().Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	spaces := []Space{}
+	if err := cursor.All(ctx, &spaces); err != nil {
+		return nil, err
+	}
+
+	for i := range spaces {
+		if err := r.decrypt(&spaces[i]); err != nil {
+			return nil, err
+		}
+	}
+	return spaces, nil
+}
+
+// Stream decodes the spaces matching the filter from the cursor and passes them
+// to fn in chunks of up to chunkSize, so that large results are never held in memory
+func (r *SpaceRepository) Stream(ctx context.Context, filter interface{}, chunkSize int, fn func([]Space) error, opts ...*options.FindOptions) error {
+	opts = append(opts, options.Find().SetBatchSize(int32(chunkSize)))
+	cursor, err := r.collection().Find(ctx, filter, opts...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	chunk := make([]Space, 0, chunkSize)
+	for cursor.Next(ctx) {
+		var space Space
+		if err := cursor.Decode(&space); err != nil {
+			return err
+		}
+		if err := r.decrypt(&space); err != nil {
+			return err
+		}
+		chunk = append(chunk, space)
+		if len(chunk) == chunkSize {
+			if err := fn(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		return fn(chunk)
+	}
+	return nil
+}
+
+// FindOne returns the space matching the filter or mongo.ErrNoDocuments
+func (r *SpaceRepository) FindOne(ctx context.Context, filter interface{}) (*Space, error) {
+	var space Space
+	if err := r.collection().FindOne(ctx, filter).Decode(&space); err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(&space); err != nil {
+		return nil, err
+	}
+	return &space, nil
+}
+
+// Insert stores a new space and sets its ID
+func (r *SpaceRepository) Insert(ctx context.Context, space *Space) error {
+	stored := *space
+	assignedTo, err := r.encrypt(space.AssignedTo)
+	if err != nil {
+		return err
+	}
+	stored.AssignedTo = assignedTo
+
+	result, err := r.collection().InsertOne(ctx, stored)
+	if err != nil {
+		return err
+	}
+	space.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// InsertMany stores new spaces without stopping at the first one that fails. The
+// spaces that were not stored are reported by index in a mongo.BulkWriteException.
+func (r *SpaceRepository) InsertMany(ctx context.Context, spaces []*Space) error {
+	docs := make([]interface{}, 0, len(spaces))
+	for _, space := range spaces {
+		stored := *space
+		assignedTo, err := r.encrypt(space.AssignedTo)
+		if err != nil {
+			return err
+		}
+		stored.AssignedTo = assignedTo
+		docs = append(docs, stored)
+	}
+
+	_, err := r.collection().InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// UpdateAssignment stores the assignment and status of a live space that is still
+// at the given version, and reports whether it was. A space that was deleted or
+// changed since it was read is left as it is.
+func (r *SpaceRepository) UpdateAssignment(ctx context.Context, space *Space, version int64) (bool, error) {
+	assignedTo, err := r.encrypt(space.AssignedTo)
+	if err != nil {
+		return false, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"assigned_to":   assignedTo,
+			"assigned_type": space.AssignedType,
+			"assigned_id":   space.AssignedID,
+			"status":        space.Status,
+			"updated_at":    space.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := r.collection().UpdateOne(ctx, notDeleted(bson.M{"space_id": space.SpaceID, "version": version}), update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// SoftDelete marks a live space as deleted and reports whether it was found. A
+// deleted space no longer counts as occupied, so its assignment period ends.
+func (r *SpaceRepository) SoftDelete(ctx context.Context, spaceID string, deletedAt time.Time) (bool, error) {
+	result, err := r.collection().UpdateOne(ctx,
+		notDeleted(bson.M{"space_id": spaceID}),
+		bson.M{
+			"$set": bson.M{"deleted_at": deletedAt, "updated_at": deletedAt},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil || result.MatchedCount == 0 {
+		return false, err
+	}
+	return true, r.endAssignmentPeriods(ctx, bson.M{"space_id": spaceID}, deletedAt)
+}
+
+// RecordAssignment keeps the assignment history in step with a change of a space
+// from previous: the open period of the space ends and, while the space is
+// occupied, a new one starts. Changes that keep the assignee are not recorded.
+func (r *SpaceRepository) RecordAssignment(ctx context.Context, previous Space, space *Space) error {
+	if previous.Status == space.Status && equalRef(previous.AssignedType, space.AssignedType) &&
+		equalRef(previous.AssignedID, space.AssignedID) && equalRef(previous.AssignedTo, space.AssignedTo) {
+		return nil
+	}
+
+	if err := r.endAssignmentPeriods(ctx, bson.M{"space_id": space.SpaceID}, space.UpdatedAt); err != nil {
+		return err
+	}
+	if space.Status != "occupied" || space.DeletedAt != nil {
+		return nil
+	}
+	_, err := r.dbService.GetCollection(collectionAssignmentHistory).InsertOne(ctx, NewAssignmentPeriod(space))
+	return err
+}
+
+// endAssignmentPeriods ends the open assignment periods of the spaces matching the filter
+func (r *SpaceRepository) endAssignmentPeriods(ctx context.Context, filter bson.M, endedAt time.Time) error {
+	filter["ended_at"] = nil
+	_, err := r.dbService.GetCollection(collectionAssignmentHistory).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"ended_at": endedAt}})
+	return err
+}
+
+// SetAssignedTo overwrites the assigned_to field of all spaces matching the filter.
+// It only syncs the display name of the assignee, updated_at keeps marking the last
+// change of the assignment, which ReleaseStale and the assignment history rely on.
+func (r *SpaceRepository) SetAssignedTo(ctx context.Context, filter interface{}, assignedTo string) (*mongo.UpdateResult, error) {
+	stored, err := r.encrypt(&assignedTo)
+	if err != nil {
+		return nil, err
+	}
+	return r.collection().UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"assigned_to": stored},
+		"$inc": bson.M{"version": 1},
+	})
+}
+
+// ReleaseStale frees the occupied spaces whose assignment was last changed before
+// cutoff, optionally only those assigned to the given type, and returns their
+// number. With dryRun the spaces are only counted.
+func (r *SpaceRepository) ReleaseStale(ctx context.Context, cutoff time.Time, assignedType string, dryRun bool) (int64, error) {
+	filter := notDeleted(bson.M{"status": "occupied", "updated_at": bson.M{"$lt": cutoff}})
+	if assignedType != "" {
+		filter["assigned_type"] = assignedType
+	}
+	if dryRun {
+		return r.collection().CountDocuments(ctx, filter)
+	}
+
+	// The released spaces are looked up first to end their assignment periods
+	spaceIDs, err := r.collection().Distinct(ctx, "space_id", filter)
+	if err != nil || len(spaceIDs) == 0 {
+		return 0, err
+	}
+	filter["space_id"] = bson.M{"$in": spaceIDs}
+
+	now := time.Now()
+	result, err := r.collection().UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{
+			"assigned_to":   nil,
+			"assigned_type": nil,
+			"assigned_id":   nil,
+			"status":        "available",
+			"updated_at":    now,
+		},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, r.endAssignmentPeriods(ctx, bson.M{"space_id": bson.M{"$in": spaceIDs}}, now)
+}
+
+// OccupancyByFloor aggregates space occupancy per building and floor number, ordered by building and floor
+func (r *SpaceRepository) OccupancyByFloor(ctx context.Context) ([]FloorOccupancy, error) {
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: notDeleted(bson.M{})}}}, floorStatusStages...)
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []floorStatusRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return floorOccupancy(rows), nil
+}
+
+// floorStatusStages count the spaces and capacity per building, floor number and
+// status, ordered by building and floor
+var floorStatusStages = mongo.Pipeline{
+	{{Key: "$group", Value: bson.M{
+		"_id":      bson.M{"building_id": "$building_id", "floor": "$floor", "status": "$status"},
+		"spaces":   bson.M{"$sum": 1},
+		"capacity": bson.M{"$sum": "$capacity"},
+	}}},
+	{{Key: "$sort", Value: bson.D{{Key: "_id.building_id", Value: 1}, {Key: "_id.floor", Value: 1}}}},
+}
+
+// floorStatusRow is a result row of floorStatusStages
+type floorStatusRow struct {
+	ID struct {
+		BuildingID *string `bson:"building_id"`
+		Floor      int     `bson:"floor"`
+		Status     string  `bson:"status"`
+	} `bson:"_id"`
+	Spaces   int `bson:"spaces"`
+	Capacity int `bson:"capacity"`
+}
+
+// floorOccupancy folds the rows of floorStatusStages into the occupancy per
+// building and floor number. Spaces outside the hierarchy have no building.
+func floorOccupancy(rows []floorStatusRow) []FloorOccupancy {
+	floors := []FloorOccupancy{}
+	for _, row := range rows {
+		occupancy := statusOccupancy(row.ID.Status, row.Spaces, row.Capacity)
+
+		// Rows are sorted, so the statuses of a floor are adjacent
+		last := len(floors) - 1
+		if last < 0 || floors[last].Floor != row.ID.Floor || !equalRef(floors[last].BuildingID, row.ID.BuildingID) {
+			floors = append(floors, FloorOccupancy{BuildingID: row.ID.BuildingID, Floor: row.ID.Floor})
+			last++
+		}
+		floors[last].Occupancy.add(occupancy)
+	}
+	for i := range floors {
+		floors[i].Occupancy.computeRate()
+	}
+	return floors
+}
+
+// Summary aggregates the occupancy of the live spaces in total, per type and per
+// building and floor number with a single $facet aggregation, types and floors
+// are ordered
+func (r *SpaceRepository) Summary(ctx context.Context) (*SpaceSummary, error) {
+	byStatus := func(key interface{}) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{
+				"_id":      bson.M{"key": key, "status": "$status"},
+				"spaces":   bson.M{"$sum": 1},
+				"capacity": bson.M{"$sum": "$capacity"},
+			}},
+			bson.M{"$sort": bson.D{{Key: "_id.key", Value: 1}}},
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{})}},
+		{{Key: "$facet", Value: bson.M{
+			"total":    byStatus(nil),
+			"by_type":  byStatus("$type"),
+			"by_floor": floorStatusStages,
+		}}},
+	}
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type counts struct {
+		Spaces   int `bson:"spaces"`
+		Capacity int `bson:"capacity"`
+	}
+	var facets []struct {
+		Total []struct {
+			ID struct {
+				Status string `bson:"status"`
+			} `bson:"_id"`
+			counts `bson:",inline"`
+		} `bson:"total"`
+		ByType []struct {
+			ID struct {
+				Key    string `bson:"key"`
+				Status string `bson:"status"`
+			} `bson:"_id"`
+			counts `bson:",inline"`
+		} `bson:"by_type"`
+		ByFloor []floorStatusRow `bson:"by_floor"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	summary := &SpaceSummary{ByType: []TypeOccupancy{}, ByFloor: []FloorOccupancy{}, GeneratedAt: time.Now()}
+	if len(facets) == 0 {
+		return summary, nil
+	}
+	for _, row := range facets[0].Total {
+		summary.Total.add(statusOccupancy(row.ID.Status, row.Spaces, row.Capacity))
+	}
+	summary.Total.computeRate()
+
+	// Rows are sorted, so the statuses of a type are adjacent
+	for _, row := range facets[0].ByType {
+		if last := len(summary.ByType) - 1; last < 0 || summary.ByType[last].Type != row.ID.Key {
+			summary.ByType = append(summary.ByType, TypeOccupancy{Type: row.ID.Key})
+		}
+		summary.ByType[len(summary.ByType)-1].Occupancy.add(statusOccupancy(row.ID.Status, row.Spaces, row.Capacity))
+	}
+	for i := range summary.ByType {
+		summary.ByType[i].Occupancy.computeRate()
+	}
+	summary.ByFloor = floorOccupancy(facets[0].ByFloor)
+	return summary, nil
+}
+
+// ReencryptAssignments encrypts every assigned_to value that is stored in plaintext
+// or with a key other than the active one, and returns the number of updated spaces.
+// It is safe to run while the API is serving requests.
+func (r *SpaceRepository) ReencryptAssignments(ctx context.Context) (int, error) {
+	cursor, err := r.collection().Find(ctx,
+		bson.M{"assigned_to": bson.M{"$type": "string"}},
+		options.Find().SetProjection(bson.M{"_id": 1, "assigned_to": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID         primitive.ObjectID `bson:"_id"`
+			AssignedTo string             `bson:"assigned_to"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+		if !r.keyring.NeedsReencryption(doc.AssignedTo) {
+			continue
+		}
+
+		plaintext, err := r.keyring.Decrypt(doc.AssignedTo)
+		if err != nil {
+			return updated, err
+		}
+		ciphertext, err := r.keyring.Encrypt(plaintext)
+		if err != nil {
+			return updated, err
+		}
+
+		// Only replace the value we read, a concurrent assignment wins
+		result, err := r.collection().UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "assigned_to": doc.AssignedTo},
+			bson.M{"$set": bson.M{"assigned_to": ciphertext}},
+		)
+		if err != nil {
+			return updated, err
+		}
+		updated += int(result.ModifiedCount)
+	}
+
+	return updated, cursor.Err()
+}
+
+// equalRef reports whether two optional references are both unset or equal
+func equalRef(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// encrypt returns the stored form of an assigned_to value
+func (r *SpaceRepository) encrypt(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	encrypted, err := r.keyring.Encrypt(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// decrypt replaces the stored form of assigned_to with its plaintext
+func (r *SpaceRepository) decrypt(space *Space) error {
+	if space.AssignedTo == nil {
+		return nil
+	}
+	plaintext, err := r.keyring.Decrypt(*space.AssignedTo)
+	if err != nil {
+		return err
+	}
+	space.AssignedTo = &plaintext
+	return nil
+}
+
+// collection returns the spaces collection
+func (r *SpaceRepository) collection() *mongo.Collection {
+	return r.dbService.GetCollection(collectionSpaces)
+}
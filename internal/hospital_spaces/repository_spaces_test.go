@@ -0,0 +1,51 @@
+package hospital_spaces
+
+import "testing"
+
+func TestFloorOccupancy(t *testing.T) {
+	buildingA, buildingB := "a", "b"
+	row := func(buildingID *string, floor int, status string, spaces, capacity int) floorStatusRow {
+		var r floorStatusRow
+		r.ID.BuildingID, r.ID.Floor, r.ID.Status = buildingID, floor, status
+		r.Spaces, r.Capacity = spaces, capacity
+		return r
+	}
+
+	// Sorted by building and floor like floorStatusStages returns them
+	floors := floorOccupancy([]floorStatusRow{
+		row(nil, 2, "available", 1, 1),
+		row(&buildingA, 2, "available", 2, 4),
+		row(&buildingA, 2, "occupied", 2, 4),
+		row(&buildingB, 2, "maintenance", 1, 2),
+		row(&buildingB, 3, "occupied", 1, 1),
+	})
+
+	want := []struct {
+		buildingID *string
+		floor      int
+		total      int
+		occupied   int
+		rate       float64
+	}{
+		{nil, 2, 1, 0, 0},
+		{&buildingA, 2, 4, 2, 0.5},
+		{&buildingB, 2, 1, 0, 0},
+		{&buildingB, 3, 1, 1, 1},
+	}
+	if len(floors) != len(want) {
+		t.Fatalf("expected %d floors, got %d: %+v", len(want), len(floors), floors)
+	}
+	for i, w := range want {
+		got := floors[i]
+		if !equalRef(got.BuildingID, w.buildingID) || got.Floor != w.floor ||
+			got.Occupancy.TotalSpaces != w.total || got.Occupancy.OccupiedSpaces != w.occupied || got.Occupancy.OccupancyRate != w.rate {
+			t.Errorf("floor %d: expected building %v floor %d with %d/%d spaces, got %+v", i, w.buildingID, w.floor, w.occupied, w.total, got)
+		}
+	}
+}
+
+func TestFloorOccupancyEmpty(t *testing.T) {
+	if floors := floorOccupancy(nil); floors == nil || len(floors) != 0 {
+		t.Errorf("expected an empty list, got %v", floors)
+	}
+}
@@ -2,16 +2,18 @@ package hospital_spaces
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/auth"
 	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rosadsky/ros-project-backend/internal/fieldcrypt"
 )
 
 type SpaceAPIRouter struct {
 	spaceService *SpaceServiceImpl
 }
 
-func NewSpaceAPIRouter(dbService *db_service.DbService) *SpaceAPIRouter {
+func NewSpaceAPIRouter(dbService *db_service.DbService, keyring *fieldcrypt.Keyring) *SpaceAPIRouter {
 	return &SpaceAPIRouter{
-		spaceService: NewSpaceServiceImpl(dbService),
+		spaceService: NewSpaceServiceImpl(dbService, keyring),
 	}
 }
 
@@ -25,19 +27,101 @@ func (router *SpaceAPIRouter) RegisterRoutes(engine *gin.Engine) {
 			spaces.GET("", router.spaceService.GetSpaces)          // READ (all)
 			spaces.PUT("/:id", router.spaceService.UpdateSpace)    // UPDATE
 			spaces.DELETE("/:id", router.spaceService.DeleteSpace) // DELETE
+			spaces.GET("/summary", router.spaceService.GetSpaceSummary)
+			spaces.POST("/:id/restore", router.spaceService.RestoreSpace)
+			spaces.PUT("/:id/location", router.spaceService.UpdateSpaceLocation)
+			spaces.GET("/:id/equipment", router.spaceService.GetSpaceEquipment)
+		}
+		api.POST("/spaces:method", customMethods("method", map[string]gin.HandlerFunc{
+			":import": router.spaceService.ImportSpaces,
+			":batch":  router.spaceService.BatchSpaces,
+		}))
+		api.GET("/spaces:method", customMethods("method", map[string]gin.HandlerFunc{":export": router.spaceService.ExportSpaces}))
+
+		// Facility hierarchy routes - building / floor / wing
+		buildings := api.Group("/buildings")
+		{
+			buildings.POST("", router.spaceService.CreateBuilding)
+			buildings.GET("", router.spaceService.GetBuildings)
+			buildings.GET("/:id", router.spaceService.GetBuilding)
+			buildings.PUT("/:id", router.spaceService.UpdateBuilding)
+			buildings.DELETE("/:id", router.spaceService.DeleteBuilding)
+		}
+
+		floors := api.Group("/floors")
+		{
+			floors.POST("", router.spaceService.CreateFloor)
+			floors.GET("", router.spaceService.GetFloors)
+			floors.GET("/:id", router.spaceService.GetFloor)
+			floors.PUT("/:id", router.spaceService.UpdateFloor)
+			floors.DELETE("/:id", router.spaceService.DeleteFloor)
+		}
+
+		wings := api.Group("/wings")
+		{
+			wings.POST("", router.spaceService.CreateWing)
+			wings.GET("", router.spaceService.GetWings)
+			wings.GET("/:id", router.spaceService.GetWing)
+			wings.PUT("/:id", router.spaceService.UpdateWing)
+			wings.DELETE("/:id", router.spaceService.DeleteWing)
+		}
+
+		api.GET("/facility/tree", router.spaceService.GetFacilityTree)
+
+		departments := api.Group("/departments")
+		{
+			departments.POST("", router.spaceService.CreateDepartment)
+			departments.GET("", router.spaceService.GetDepartments)
+			departments.GET("/:id", router.spaceService.GetDepartment)
+			departments.PUT("/:id", router.spaceService.UpdateDepartment)
+			departments.DELETE("/:id", router.spaceService.DeleteDepartment)
+			departments.GET("/:id/spaces", router.spaceService.GetDepartmentSpaces)
+		}
+
+		patients := api.Group("/patients")
+		{
+			patients.POST("", auth.RequireRole(auth.RoleClinician, auth.RoleAdmin), router.spaceService.CreatePatient)
+			patients.GET("", router.spaceService.GetPatients)
+			patients.GET("/:id", router.spaceService.GetPatient)
+			patients.DELETE("/:id", auth.RequireRole(auth.RoleClinician, auth.RoleAdmin), router.spaceService.DeletePatient)
+		}
+
+		equipment := api.Group("/equipment")
+		{
+			equipment.POST("", router.spaceService.CreateEquipment)
+			equipment.GET("", router.spaceService.GetEquipment)
+			equipment.GET("/:id", router.spaceService.GetEquipmentItem)
+			equipment.PUT("/:id", router.spaceService.UpdateEquipment)
+			equipment.DELETE("/:id", router.spaceService.DeleteEquipment)
+			equipment.POST("/:id/move", router.spaceService.MoveEquipment)
+			equipment.GET("/:id/history", router.spaceService.GetEquipmentHistory)
 		}
 
 		ambulances := api.Group("/ambulances")
 		{
 			ambulances.POST("", router.spaceService.CreateAmbulance)
 			ambulances.GET("", router.spaceService.GetAmbulances)
+			ambulances.DELETE("/:id", router.spaceService.DeleteAmbulance)
+			ambulances.PUT("/:id/status", router.spaceService.UpdateAmbulanceStatus)
+			ambulances.POST("/:id/restore", router.spaceService.RestoreAmbulance)
 		}
+		api.POST("/ambulances:method", customMethods("method", map[string]gin.HandlerFunc{":import": router.spaceService.ImportAmbulances}))
+		api.GET("/ambulances:method", customMethods("method", map[string]gin.HandlerFunc{":export": router.spaceService.ExportAmbulances}))
+
+		reports := api.Group("/reports")
+		{
+			reports.GET("/utilization", router.spaceService.GetUtilizationReport)
+			reports.GET("/ambulances", router.spaceService.GetAmbulanceReport)
+		}
+
+		api.GET("/forecast/occupancy", router.spaceService.GetOccupancyForecast)
 	}
 
 	// Health check endpoint
 	// @Summary Health check
-	// @Description Check the health status of the API service
+	// @Description Check the health status of the API service. Does not check dependencies, use /healthz/live and /healthz/ready instead.
 	// @Tags Health
+	// @Deprecated
 	// @Accept json
 	// @Produce json
 	// @Success 200 {object} map[string]string "Service is healthy"
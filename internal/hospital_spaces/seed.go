@@ -0,0 +1,81 @@
+package hospital_spaces
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// demoSpaceTypes are cycled through when seeding demo spaces
+var demoSpaceTypes = []string{"patient_room", "patient_room", "examination_room", "operating_room", "icu"}
+
+// demoAmbulanceTypes are cycled through when seeding demo ambulances
+var demoAmbulanceTypes = []string{"type_a", "type_b", "type_c"}
+
+// SeedResult reports the demo data created by SeedDemoData
+type SeedResult struct {
+	Spaces            int `json:"spaces"`
+	Ambulances        int `json:"ambulances"`
+	SkippedSpaces     int `json:"skipped_spaces"`
+	SkippedAmbulances int `json:"skipped_ambulances"`
+}
+
+// SeedDemoData creates demo spaces spread over floors 1 to floors and demo
+// ambulances. Items are named by their number, items whose name already exists
+// are skipped, so seeding twice does not duplicate data.
+func (s *SpaceServiceImpl) SeedDemoData(ctx context.Context, spaces, ambulances, floors int) (*SeedResult, error) {
+	if floors < 1 {
+		floors = 1
+	}
+	result := &SeedResult{}
+
+	perFloor := (spaces + floors - 1) / floors
+	for i := 0; i < spaces; i++ {
+		floor := i/perFloor + 1
+		name := fmt.Sprintf("Demo %d%02d", floor, i%perFloor+1)
+		exists, err := s.exists(ctx, collectionSpaces, notDeleted(bson.M{"name": name}))
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.SkippedSpaces++
+			continue
+		}
+
+		space := NewSpace(SpaceCreateRequest{
+			Name:     name,
+			Type:     demoSpaceTypes[i%len(demoSpaceTypes)],
+			Floor:    &floor,
+			Capacity: 1 + i%4,
+		})
+		if err := s.spaces.Insert(ctx, space); err != nil {
+			return result, err
+		}
+		result.Spaces++
+	}
+
+	for i := 0; i < ambulances; i++ {
+		name := fmt.Sprintf("Demo Ambulance %d", i+1)
+		exists, err := s.exists(ctx, collectionAmbulances, notDeleted(bson.M{"name": name}))
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.SkippedAmbulances++
+			continue
+		}
+
+		ambulance := NewAmbulance(AmbulanceCreateRequest{
+			Name:     name,
+			Type:     demoAmbulanceTypes[i%len(demoAmbulanceTypes)],
+			Location: "Demo Station",
+		})
+		if _, err := s.dbService.GetCollection(collectionAmbulances).InsertOne(ctx, ambulance); err != nil {
+			return result, err
+		}
+		result.Ambulances++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package hospital_spaces
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errNoSnapshot is returned for points in time before the oldest kept snapshot
+var errNoSnapshot = errors.New("no occupancy snapshot is kept for this point in time")
+
+// snapshotBatchSize is the number of space states written per insert
+const snapshotBatchSize = 1000
+
+// TakeSnapshot writes the occupancy state of all live spaces to the snapshots.
+// The snapshot is skipped when one was taken less than skipWithin ago, so that
+// replicas running the job at the same time do not duplicate snapshots.
+func TakeSnapshot(ctx context.Context, dbService *db_service.DbService, skipWithin time.Duration) (*SpaceSnapshot, error) {
+	snapshots := dbService.GetCollection(collectionSpaceSnapshots)
+	entries := dbService.GetCollection(collectionSpaceSnapshotEntries)
+	snapshot := &SpaceSnapshot{ID: primitive.NewObjectID(), TakenAt: time.Now()}
+
+	count, err := snapshots.CountDocuments(ctx, bson.M{"taken_at": bson.M{"$gt": snapshot.TakenAt.Add(-skipWithin)}}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return nil, err
+	}
+
+	cursor, err := dbService.GetCollection(collectionSpaces).Find(ctx, notDeleted(bson.M{}),
+		options.Find().SetProjection(bson.M{"_id": 0, "space_id": 1, "status": 1, "assigned_type": 1, "assigned_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	// Entries of a failed snapshot are removed, readers only see snapshots that were written completely
+	fail := func(err error) (*SpaceSnapshot, error) {
+		_, _ = entries.DeleteMany(context.WithoutCancel(ctx), bson.M{"snapshot_id": snapshot.ID})
+		return nil, err
+	}
+	batch := make([]interface{}, 0, snapshotBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := entries.InsertMany(ctx, batch)
+		batch = batch[:0]
+		return err
+	}
+	for cursor.Next(ctx) {
+		var state SnapshotSpace
+		if err := cursor.Decode(&state); err != nil {
+			return fail(err)
+		}
+		state.SnapshotID, state.TakenAt = snapshot.ID, snapshot.TakenAt
+		batch = append(batch, state)
+		snapshot.Spaces++
+		if len(batch) == snapshotBatchSize {
+			if err := flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fail(err)
+	}
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+
+	if _, err := snapshots.InsertOne(ctx, snapshot); err != nil {
+		return fail(err)
+	}
+	return snapshot, nil
+}
+
+// RunSnapshots takes a snapshot immediately and then every interval until ctx is
+// cancelled, and deletes the snapshots older than retention
+func RunSnapshots(ctx context.Context, dbService *db_service.DbService, interval, retention time.Duration, logger zerolog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		snapshotCtx, cancel := dbService.WriteContext(ctx)
+		snapshot, err := TakeSnapshot(snapshotCtx, dbService, interval/2)
+		if err == nil {
+			err = deleteSnapshots(snapshotCtx, dbService, time.Now().Add(-retention))
+		}
+		cancel()
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.Warn().Err(err).Msg("Failed to snapshot space occupancy")
+		case err == nil && snapshot != nil:
+			logger.Debug().Int("spaces", snapshot.Spaces).Msg("Snapshotted space occupancy")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteSnapshots deletes the snapshots taken before cutoff, the snapshots first so
+// that no reader picks one whose entries are gone
+func deleteSnapshots(ctx context.Context, dbService *db_service.DbService, cutoff time.Time) error {
+	filter := bson.M{"taken_at": bson.M{"$lt": cutoff}}
+	if _, err := dbService.GetCollection(collectionSpaceSnapshots).DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := dbService.GetCollection(collectionSpaceSnapshotEntries).DeleteMany(ctx, filter)
+	return err
+}
+
+// SpacesAsOf reconstructs the spaces as they were at asOf from the nearest
+// snapshot before it and the assignment history after the snapshot. Names, types
+// and locations are the current ones; spaces created after asOf or deleted
+// before it are left out. The history only records occupied periods, so a space
+// that stopped being occupied after the snapshot is reported as available.
+func (s *SpaceServiceImpl) SpacesAsOf(ctx context.Context, asOf time.Time) ([]Space, error) {
+	var snapshot SpaceSnapshot
+	err := s.dbService.GetCollection(collectionSpaceSnapshots).FindOne(ctx,
+		bson.M{"taken_at": bson.M{"$lte": asOf}},
+		options.FindOne().SetSort(bson.D{{Key: "taken_at", Value: -1}}),
+	).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, errNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.dbService.GetCollection(collectionSpaceSnapshotEntries).Find(ctx, bson.M{"snapshot_id": snapshot.ID})
+	if err != nil {
+		return nil, err
+	}
+	defer entries.Close(ctx)
+
+	states := make(map[string]SnapshotSpace, snapshot.Spaces)
+	for entries.Next(ctx) {
+		var state SnapshotSpace
+		if err := entries.Decode(&state); err != nil {
+			return nil, err
+		}
+		states[state.SpaceID] = state
+	}
+	if err := entries.Err(); err != nil {
+		return nil, err
+	}
+
+	// Spaces that were live in the snapshot or created after it, unless deleted by asOf
+	found, err := s.spaces.Find(ctx, bson.M{
+		"created_at": bson.M{"$lte": asOf},
+		"$nor":       bson.A{bson.M{"deleted_at": bson.M{"$lte": asOf}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	spaces := make([]Space, 0, len(found))
+	for _, space := range found {
+		if _, ok := states[space.SpaceID]; ok || space.CreatedAt.After(snapshot.TakenAt) {
+			spaces = append(spaces, space)
+		}
+	}
+
+	cursor, err := s.dbService.GetCollection(collectionAssignmentHistory).Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"started_at": bson.M{"$gt": snapshot.TakenAt, "$lte": asOf}},
+		bson.M{"ended_at": bson.M{"$gt": snapshot.TakenAt, "$lte": asOf}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var periods []AssignmentPeriod
+	if err := cursor.All(ctx, &periods); err != nil {
+		return nil, err
+	}
+
+	// Replay the starts and ends of the periods in order, ends first so that a
+	// change of assignee ends up with the new one
+	type event struct {
+		at    time.Time
+		start bool
+		state SnapshotSpace
+	}
+	var events []event
+	inRange := func(at time.Time) bool { return at.After(snapshot.TakenAt) && !at.After(asOf) }
+	for _, period := range periods {
+		if inRange(period.StartedAt) {
+			events = append(events, event{at: period.StartedAt, start: true, state: SnapshotSpace{
+				SpaceID: period.SpaceID, Status: "occupied", AssignedType: period.AssignedType, AssignedID: period.AssignedID,
+			}})
+		}
+		if period.EndedAt != nil && inRange(*period.EndedAt) {
+			events = append(events, event{at: *period.EndedAt, state: SnapshotSpace{SpaceID: period.SpaceID, Status: "available"}})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return !events[i].start && events[j].start
+	})
+	for _, e := range events {
+		states[e.state.SpaceID] = e.state
+	}
+
+	for i := range spaces {
+		state, ok := states[spaces[i].SpaceID]
+		if !ok {
+			// Created after the snapshot and never occupied until asOf
+			state = SnapshotSpace{SpaceID: spaces[i].SpaceID, Status: "available"}
+		}
+		state.apply(&spaces[i])
+		spaces[i].DeletedAt = nil
+	}
+	return spaces, nil
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// redacted replaces secrets in log output
+const redacted = "xxxxx"
+
+// New creates the service logger from the logging configuration. The level is
+// applied globally so that it can be changed on configuration reload with SetLevel.
+func New(cfg config.LoggingConfig) zerolog.Logger {
+	return NewWithOutput(cfg, os.Stdout)
+}
+
+// NewWithOutput creates the service logger writing to out, e.g. stderr for command
+// line tools that print their results to stdout
+func NewWithOutput(cfg config.LoggingConfig, out io.Writer) zerolog.Logger {
+	SetLevel(cfg.Level)
+
+	output := out
+	if cfg.Format == "console" {
+		output = zerolog.ConsoleWriter{Out: out, TimeFormat: time.RFC3339}
+	}
+	return zerolog.New(output).With().Timestamp().Logger()
+}
+
+// SetLevel changes the global log level, invalid levels are ignored as they are rejected by config validation
+func SetLevel(level string) {
+	if parsed, err := zerolog.ParseLevel(level); err == nil {
+		zerolog.SetGlobalLevel(parsed)
+	}
+}
+
+// FromContext returns the request logger stored in ctx by the middleware, or the
+// global default logger outside of requests
+func FromContext(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
+
+// RedactURI masks the password of a connection URI so that it can be logged
+func RedactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		// Do not risk logging an unparsable URI that may contain credentials
+		return redacted
+	}
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+	}
+	return parsed.String()
+}
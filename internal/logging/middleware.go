@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// HeaderRequestID carries the request ID from the client and back in the response
+	HeaderRequestID = "X-Request-ID"
+
+	requestIDContextKey = "request_id"
+	maxRequestIDLength  = 128
+)
+
+// Middleware assigns each request an ID, taken from the X-Request-ID header or
+// generated, and echoes it in the response. It stores a logger carrying the request
+// ID in the request context and writes one access log line per request. Successful
+// requests to quietPaths, such as probes and metrics scrapes, are logged at debug level.
+func Middleware(logger zerolog.Logger, quietPaths ...string) gin.HandlerFunc {
+	quiet := make(map[string]bool, len(quietPaths))
+	for _, path := range quietPaths {
+		quiet[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(HeaderRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+		c.Set(requestIDContextKey, requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		requestLogger := logger.With().Str("request_id", requestID).Logger()
+		c.Request = c.Request.WithContext(requestLogger.WithContext(c.Request.Context()))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = requestLogger.Error()
+		case status >= http.StatusBadRequest:
+			event = requestLogger.Warn()
+		case quiet[c.FullPath()]:
+			event = requestLogger.Debug()
+		default:
+			event = requestLogger.Info()
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+		event.Ctx(c.Request.Context()).
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Int("bytes", max(c.Writer.Size(), 0)).
+			Msg("Request handled")
+	}
+}
+
+// RequestID returns the ID assigned to the request by the middleware
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// spaceCount is a row of the spaces aggregation
+type spaceCount struct {
+	ID struct {
+		Type   string `bson:"type"`
+		Floor  int    `bson:"floor"`
+		Status string `bson:"status"`
+	} `bson:"_id"`
+	Count int `bson:"count"`
+}
+
+// ambulanceCount is a row of the ambulances aggregation
+type ambulanceCount struct {
+	Status string `bson:"_id"`
+	Count  int    `bson:"count"`
+}
+
+// RunDomainRefresh refreshes the space and ambulance gauges immediately and then
+// every interval until ctx is cancelled
+func (m *Metrics) RunDomainRefresh(ctx context.Context, dbService *db_service.DbService, interval time.Duration, logger zerolog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RefreshDomain(ctx, dbService); err != nil && ctx.Err() == nil {
+			m.refreshFailures.Inc()
+			logger.Warn().Err(err).Msg("Failed to refresh domain metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshDomain recomputes the space and ambulance gauges from aggregation queries
+func (m *Metrics) RefreshDomain(ctx context.Context, dbService *db_service.DbService) error {
+	ctx, cancel := dbService.AggregateContext(ctx)
+	defer cancel()
+
+	var spaces []spaceCount
+	// Deleted spaces and ambulances are not counted
+	if err := aggregate(ctx, dbService, "spaces", bson.A{
+		bson.M{"$match": bson.M{"deleted_at": nil}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"type": "$type", "floor": "$floor", "status": "$status"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}, &spaces); err != nil {
+		return err
+	}
+
+	var ambulances []ambulanceCount
+	if err := aggregate(ctx, dbService, "ambulances", bson.A{
+		bson.M{"$match": bson.M{"deleted_at": nil}},
+		bson.M{"$group": bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}},
+	}, &ambulances); err != nil {
+		return err
+	}
+
+	// Reset so that combinations that no longer exist drop to absent rather than keep a stale value
+	m.spaces.Reset()
+	for _, row := range spaces {
+		m.spaces.WithLabelValues(row.ID.Type, strconv.Itoa(row.ID.Floor), row.ID.Status).Set(float64(row.Count))
+	}
+	m.ambulances.Reset()
+	for _, row := range ambulances {
+		m.ambulances.WithLabelValues(row.Status).Set(float64(row.Count))
+	}
+	m.refreshedAt.SetToCurrentTime()
+	return nil
+}
+
+// aggregate runs the pipeline on the collection and decodes all results
+func aggregate(ctx context.Context, dbService *db_service.DbService, collectionName string, pipeline bson.A, results interface{}) error {
+	cursor, err := dbService.GetCollection(collectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate %s: %w", collectionName, err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, results); err != nil {
+		return fmt.Errorf("failed to decode %s aggregation: %w", collectionName, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hospital_spaces"
+
+// Metrics holds the Prometheus collectors of the service
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration  *prometheus.HistogramVec
+	mongoDuration *prometheus.HistogramVec
+	mongoErrors   *prometheus.CounterVec
+
+	spaces          *prometheus.GaugeVec
+	ambulances      *prometheus.GaugeVec
+	refreshFailures prometheus.Counter
+	refreshedAt     prometheus.Gauge
+}
+
+// New creates the service metrics on their own registry, together with the Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "mongodb",
+			Name:      "command_duration_seconds",
+			Help:      "Duration of MongoDB commands by command name and outcome.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"command", "outcome"}),
+		mongoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "mongodb",
+			Name:      "command_errors_total",
+			Help:      "Number of failed MongoDB commands by command name.",
+		}, []string{"command"}),
+		spaces: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "spaces",
+			Help:      "Number of spaces by type, floor and status.",
+		}, []string{"type", "floor", "status"}),
+		ambulances: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "ambulances",
+			Help:      "Number of ambulances by status.",
+		}, []string{"status"}),
+		refreshFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "domain_metrics_refresh_failures_total",
+			Help:      "Number of failed refreshes of the space and ambulance gauges.",
+		}),
+		refreshedAt: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "domain_metrics_refreshed_timestamp_seconds",
+			Help:      "Unix time of the last successful refresh of the space and ambulance gauges.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.mongoDuration,
+		m.mongoErrors,
+		m.spaces,
+		m.ambulances,
+		m.refreshFailures,
+		m.refreshedAt,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterRoutes registers the /metrics endpoint
+func (m *Metrics) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/metrics", gin.WrapH(m.Handler()))
+}
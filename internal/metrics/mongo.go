@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandMonitor returns a MongoDB command monitor recording the latency and errors of database commands
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.mongoDuration.WithLabelValues(e.CommandName, "success").Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.mongoDuration.WithLabelValues(e.CommandName, "error").Observe(e.Duration.Seconds())
+			m.mongoErrors.WithLabelValues(e.CommandName).Inc()
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+)
+
+// HeaderRequestTimeout lets clients ask for a shorter or longer request deadline
+const HeaderRequestTimeout = "X-Request-Timeout"
+
+// RequestTimeout applies the deadline requested with the X-Request-Timeout header to
+// the request context, capped at maxTimeout. The header takes a Go duration such as
+// "2s" or "500ms", or a number of seconds. Database contexts derived from the request
+// context end at whichever comes first, this deadline or their own operation timeout.
+func RequestTimeout(maxTimeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(HeaderRequestTimeout)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		timeout, err := parseTimeout(value)
+		if err != nil || timeout <= 0 {
+			problem.Write(c, problem.Validation("invalid_request_timeout", "Invalid "+HeaderRequestTimeout+" header"))
+			return
+		}
+		if timeout > maxTimeout {
+			timeout = maxTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// parseTimeout parses a Go duration or a plain number of seconds
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
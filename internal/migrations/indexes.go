@@ -0,0 +1,120 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RequiredIndex is a unique index on a single field that the migrations create and
+// the service relies on to keep IDs unique. A partial index only covers live
+// records or open periods, see softDelete and createAssignmentHistory.
+type RequiredIndex struct {
+	Collection string
+	Field      string
+	Partial    bool
+}
+
+// String names the index for readiness reports, e.g. spaces.space_id (partial)
+func (i RequiredIndex) String() string {
+	if i.Partial {
+		return i.Collection + "." + i.Field + " (partial)"
+	}
+	return i.Collection + "." + i.Field
+}
+
+// RequiredIndexes lists the unique indexes of the applied migrations. They are
+// matched by field rather than by name, databases migrated by earlier releases
+// name the partial ID indexes space_id_1 and ambulance_id_1.
+func RequiredIndexes() []RequiredIndex {
+	return []RequiredIndex{
+		{Collection: "spaces", Field: "space_id", Partial: true},
+		{Collection: "ambulances", Field: "ambulance_id", Partial: true},
+		{Collection: "assignment_history", Field: "space_id", Partial: true},
+		{Collection: "ambulance_runs", Field: "ambulance_id", Partial: true},
+		{Collection: "buildings", Field: "building_id"},
+		{Collection: "floors", Field: "floor_id"},
+		{Collection: "wings", Field: "wing_id"},
+		{Collection: "departments", Field: "department_id"},
+		{Collection: "equipment", Field: "equipment_id"},
+		{Collection: "equipment", Field: "serial_number"},
+		{Collection: "patients", Field: "patient_id"},
+		{Collection: "patients", Field: "mrn"},
+		{Collection: "patients", Field: "pseudonym"},
+		{Collection: "api_keys", Field: "key_id"},
+		{Collection: "api_keys", Field: "key_hash"},
+	}
+}
+
+// indexSpec is the part of a listed index that RequiredIndex is matched against
+type indexSpec struct {
+	Name    string   `bson:"name"`
+	Key     bson.D   `bson:"key"`
+	Unique  bool     `bson:"unique"`
+	Partial bson.Raw `bson:"partialFilterExpression"`
+}
+
+// satisfies reports whether the index is the unique index required
+func (s indexSpec) satisfies(required RequiredIndex) bool {
+	return s.Unique && len(s.Key) == 1 && s.Key[0].Key == required.Field && (len(s.Partial) > 0) == required.Partial
+}
+
+// MissingIndexes lists the required indexes the database lacks
+func MissingIndexes(ctx context.Context, db *db_service.DbService) ([]string, error) {
+	indexes := map[string][]indexSpec{}
+	for _, required := range RequiredIndexes() {
+		if _, ok := indexes[required.Collection]; ok {
+			continue
+		}
+		specs, err := listIndexes(ctx, db, required.Collection)
+		if err != nil {
+			return nil, err
+		}
+		indexes[required.Collection] = specs
+	}
+	return missingIndexes(RequiredIndexes(), indexes), nil
+}
+
+// missingIndexes lists the required indexes that none of the listed indexes of their collection satisfies
+func missingIndexes(required []RequiredIndex, indexes map[string][]indexSpec) []string {
+	var missing []string
+	for _, index := range required {
+		found := false
+		for _, spec := range indexes[index.Collection] {
+			if spec.satisfies(index) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, index.String())
+		}
+	}
+	return missing
+}
+
+// listIndexes lists the indexes of a collection, none when it does not exist yet
+func listIndexes(ctx context.Context, db *db_service.DbService, collectionName string) ([]indexSpec, error) {
+	cursor, err := db.GetCollection(collectionName).Indexes().List(ctx)
+	if isNamespaceNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", collectionName, err)
+	}
+	var specs []indexSpec
+	if err := cursor.All(ctx, &specs); err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", collectionName, err)
+	}
+	return specs, nil
+}
+
+// isNamespaceNotFound reports the error of listing the indexes of a missing collection
+func isNamespaceNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 26 // NamespaceNotFound
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMissingIndexes(t *testing.T) {
+	partial, err := bson.Marshal(bson.M{"deleted_at": bson.M{"$type": "null"}})
+	if err != nil {
+		t.Fatalf("failed to marshal filter: %v", err)
+	}
+	required := []RequiredIndex{
+		{Collection: "spaces", Field: "space_id", Partial: true},
+		{Collection: "floors", Field: "floor_id"},
+	}
+	key := func(field string) bson.D { return bson.D{{Key: field, Value: int32(1)}} }
+
+	tests := []struct {
+		name    string
+		indexes map[string][]indexSpec
+		want    []string
+	}{
+		{
+			name: "partial index under its current name",
+			indexes: map[string][]indexSpec{
+				"spaces": {{Name: "_id_", Key: key("_id"), Unique: true}, {Name: "space_id_live", Key: key("space_id"), Unique: true, Partial: partial}},
+				"floors": {{Name: "floor_id_1", Key: key("floor_id"), Unique: true}},
+			},
+		},
+		{
+			name: "partial index under the name of earlier releases",
+			indexes: map[string][]indexSpec{
+				"spaces": {{Name: "space_id_1", Key: key("space_id"), Unique: true, Partial: partial}},
+				"floors": {{Name: "floor_id_1", Key: key("floor_id"), Unique: true}},
+			},
+		},
+		{
+			name: "full index where a partial one is required",
+			indexes: map[string][]indexSpec{
+				"spaces": {{Name: "space_id_1", Key: key("space_id"), Unique: true}},
+				"floors": {{Name: "floor_id_1", Key: key("floor_id"), Unique: true}},
+			},
+			want: []string{"spaces.space_id (partial)"},
+		},
+		{
+			name: "index that is not unique",
+			indexes: map[string][]indexSpec{
+				"spaces": {{Name: "space_id_live", Key: key("space_id"), Unique: true, Partial: partial}},
+				"floors": {{Name: "floor_id_1", Key: key("floor_id")}},
+			},
+			want: []string{"floors.floor_id"},
+		},
+		{
+			name: "compound index on the field",
+			indexes: map[string][]indexSpec{
+				"spaces": {{Name: "space_id_live", Key: key("space_id"), Unique: true, Partial: partial}},
+				"floors": {{Name: "floor_id_1_wing_id_1", Key: bson.D{{Key: "floor_id", Value: 1}, {Key: "wing_id", Value: 1}}, Unique: true}},
+			},
+			want: []string{"floors.floor_id"},
+		},
+		{
+			name:    "collections without indexes",
+			indexes: map[string][]indexSpec{},
+			want:    []string{"spaces.space_id (partial)", "floors.floor_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingIndexes(required, tt.indexes); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createIndexes creates the indexes the service relied on before migrations were
+// introduced. Creating an index that already exists with the same options is a
+// no-op, so databases set up by earlier releases pass this migration unchanged.
+func createIndexes(ctx context.Context, db *db_service.DbService) error {
+	// Create indexes for spaces collection
+	spacesCollection := db.GetCollection("spaces")
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "name", Value: 1},
+				{Key: "type", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "floor", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "space_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "floor_id", Value: 1},
+				{Key: "wing_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "wing_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "assigned_type", Value: 1},
+				{Key: "assigned_id", Value: 1},
+			},
+		},
+	}
+
+	_, err := spacesCollection.Indexes().CreateMany(ctx, indexModels)
+	if err != nil {
+		return fmt.Errorf("failed to create spaces indexes: %w", err)
+	}
+
+	// Create indexes for ambulances collection
+	ambulancesCollection := db.GetCollection("ambulances")
+	ambulanceIndexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "name", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "ambulance_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err = ambulancesCollection.Indexes().CreateMany(ctx, ambulanceIndexModels)
+	if err != nil {
+		return fmt.Errorf("failed to create ambulances indexes: %w", err)
+	}
+
+	// Create indexes for the facility hierarchy, department, equipment, patient and API key collections
+	facilityIndexes := map[string][]mongo.IndexModel{
+		"buildings": {
+			{
+				Keys:    bson.D{{Key: "building_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"floors": {
+			{
+				Keys:    bson.D{{Key: "floor_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "building_id", Value: 1}, {Key: "level", Value: 1}},
+			},
+		},
+		"wings": {
+			{
+				Keys:    bson.D{{Key: "wing_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "floor_id", Value: 1}},
+			},
+		},
+		"departments": {
+			{
+				Keys:    bson.D{{Key: "department_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "name", Value: 1}},
+			},
+		},
+		"equipment": {
+			{
+				Keys:    bson.D{{Key: "equipment_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "serial_number", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "space_id", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "maintenance_due_at", Value: 1}},
+			},
+		},
+		"patients": {
+			{
+				Keys:    bson.D{{Key: "patient_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "mrn", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "pseudonym", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"api_keys": {
+			{
+				Keys:    bson.D{{Key: "key_id", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "key_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"equipment_movements": {
+			{
+				Keys: bson.D{{Key: "equipment_id", Value: 1}, {Key: "moved_at", Value: -1}},
+			},
+		},
+		"rate_limits": {
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	}
+	for collectionName, models := range facilityIndexes {
+		if _, err := db.GetCollection(collectionName).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create %s indexes: %w", collectionName, err)
+		}
+	}
+
+	return nil
+}
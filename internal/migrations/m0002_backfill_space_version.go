@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// backfillSpaceVersion starts the version counter of spaces created before spaces were versioned
+func backfillSpaceVersion(ctx context.Context, db *db_service.DbService) error {
+	_, err := db.GetCollection("spaces").UpdateMany(ctx,
+		bson.M{"version": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"version": 1}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to backfill space versions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// softDelete prepares spaces and ambulances for tombstones. deleted_at is set to
+// null on existing records, and the unique ID indexes are replaced by partial ones
+// covering live records, i.e. those with a null deleted_at; partial indexes cannot
+// match a missing field. An index on deleted_at serves the purge of old tombstones.
+func softDelete(ctx context.Context, db *db_service.DbService) error {
+	live := bson.M{"deleted_at": bson.M{"$type": "null"}}
+
+	for _, target := range []struct {
+		collection string
+		key        string
+	}{
+		{"spaces", "space_id"},
+		{"ambulances", "ambulance_id"},
+	} {
+		collection := db.GetCollection(target.collection)
+
+		if _, err := collection.UpdateMany(ctx,
+			bson.M{"deleted_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"deleted_at": nil}},
+		); err != nil {
+			return fmt.Errorf("failed to backfill deleted_at of %s: %w", target.collection, err)
+		}
+
+		// The partial index is created under a new name before the old one is dropped,
+		// so that the IDs stay unique throughout. Databases migrated before keep the
+		// partial index under the old name, problem.From reads the field from both.
+		_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: target.key, Value: 1}},
+				Options: options.Index().SetName(target.key + "_live").SetUnique(true).SetPartialFilterExpression(live),
+			},
+			{
+				Keys: bson.D{{Key: "deleted_at", Value: 1}},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create indexes of %s: %w", target.collection, err)
+		}
+		name := target.key + "_1"
+		if _, err := collection.Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+			return fmt.Errorf("failed to drop index %s of %s: %w", name, target.collection, err)
+		}
+	}
+	return nil
+}
+
+// isIndexNotFound reports the error of dropping an index that does not exist,
+// e.g. when the migration runs again after a partial run
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 27 // IndexNotFound
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createAssignmentHistory indexes the assignment history and opens a period for
+// every occupied space, starting at its last change, so that utilization reports
+// include the assignments made before the history was recorded. A space has at
+// most one open period, which also makes the backfill safe to run again.
+func createAssignmentHistory(ctx context.Context, db *db_service.DbService) error {
+	history := db.GetCollection("assignment_history")
+	_, err := history.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "space_id", Value: 1}},
+			Options: options.Index().SetName("space_id_open").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"ended_at": bson.M{"$type": "null"}}),
+		},
+		{
+			Keys: bson.D{{Key: "started_at", Value: 1}, {Key: "ended_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create assignment history indexes: %w", err)
+	}
+
+	cursor, err := db.GetCollection("spaces").Find(ctx, bson.M{"status": "occupied", "deleted_at": nil})
+	if err != nil {
+		return fmt.Errorf("failed to find occupied spaces: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var space struct {
+			SpaceID      string    `bson:"space_id"`
+			Type         string    `bson:"type"`
+			Floor        int       `bson:"floor"`
+			BuildingID   *string   `bson:"building_id"`
+			FloorID      *string   `bson:"floor_id"`
+			AssignedType *string   `bson:"assigned_type"`
+			AssignedID   *string   `bson:"assigned_id"`
+			UpdatedAt    time.Time `bson:"updated_at"`
+		}
+		if err := cursor.Decode(&space); err != nil {
+			return fmt.Errorf("failed to decode space: %w", err)
+		}
+
+		period := bson.M{
+			"space_id":   space.SpaceID,
+			"space_type": space.Type,
+			"floor":      space.Floor,
+			"started_at": space.UpdatedAt,
+			"ended_at":   nil,
+		}
+		for key, value := range map[string]*string{"building_id": space.BuildingID, "floor_id": space.FloorID, "assigned_type": space.AssignedType, "assigned_id": space.AssignedID} {
+			if value != nil {
+				period[key] = *value
+			}
+		}
+		if space.AssignedType != nil && *space.AssignedType == "department" && space.AssignedID != nil {
+			period["department_id"] = *space.AssignedID
+		}
+
+		if _, err := history.InsertOne(ctx, period); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to open the assignment period of space %s: %w", space.SpaceID, err)
+		}
+	}
+	return cursor.Err()
+}
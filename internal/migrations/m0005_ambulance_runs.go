@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createAmbulanceRuns indexes the ambulance runs: an ambulance has at most one
+// open run, and reports select runs by their dispatch time
+func createAmbulanceRuns(ctx context.Context, db *db_service.DbService) error {
+	_, err := db.GetCollection("ambulance_runs").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "ambulance_id", Value: 1}},
+			Options: options.Index().SetName("ambulance_id_open").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"available_at": bson.M{"$type": "null"}}),
+		},
+		{
+			Keys: bson.D{{Key: "dispatched_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create ambulance run indexes: %w", err)
+	}
+	return nil
+}
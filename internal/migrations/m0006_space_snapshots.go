@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// createSpaceSnapshots indexes the occupancy snapshots by time, and the assignment
+// history by end time for replaying the periods that ended after a snapshot
+func createSpaceSnapshots(ctx context.Context, db *db_service.DbService) error {
+	if _, err := db.GetCollection("space_snapshots").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "taken_at", Value: -1}},
+	}); err != nil {
+		return fmt.Errorf("failed to create space snapshot indexes: %w", err)
+	}
+	if _, err := db.GetCollection("assignment_history").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "ended_at", Value: 1}},
+	}); err != nil {
+		return fmt.Errorf("failed to create assignment history indexes: %w", err)
+	}
+	return nil
+}
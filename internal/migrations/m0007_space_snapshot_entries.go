@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// createSpaceSnapshotEntries indexes the per-space states of the snapshots, and
+// deletes the snapshots that held all states in one document. Those grow past the
+// document size limit, and the next snapshot replaces them.
+func createSpaceSnapshotEntries(ctx context.Context, db *db_service.DbService) error {
+	if _, err := db.GetCollection("space_snapshot_entries").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "snapshot_id", Value: 1}}},
+		{Keys: bson.D{{Key: "taken_at", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("failed to create space snapshot entry indexes: %w", err)
+	}
+	if _, err := db.GetCollection("space_snapshots").DeleteMany(ctx, bson.M{"spaces": bson.M{"$type": "array"}}); err != nil {
+		return fmt.Errorf("failed to delete space snapshots without entries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+)
+
+// Migration is a versioned change of the database schema or data. Migrations run
+// in version order and each one runs once per database; a migration that fails is
+// retried on the next run, so it has to be safe to run again after a partial run.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *db_service.DbService) error
+}
+
+// ID returns the version and name of the migration, e.g. 0001_create_indexes
+func (m Migration) ID() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// VersionAssignmentHistory is the migration that starts recording the assignment
+// history. The periods it backfills start at the last change of a space rather
+// than at its assignment.
+const VersionAssignmentHistory = 4
+
+// All returns the migrations of the service in version order. New migrations are
+// appended with the next version; applied migrations must never be changed.
+func All() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_indexes", Up: createIndexes},
+		{Version: 2, Name: "backfill_space_version", Up: backfillSpaceVersion},
+		{Version: 3, Name: "soft_delete", Up: softDelete},
+		{Version: VersionAssignmentHistory, Name: "assignment_history", Up: createAssignmentHistory},
+		{Version: 5, Name: "ambulance_runs", Up: createAmbulanceRuns},
+		{Version: 6, Name: "space_snapshots", Up: createSpaceSnapshots},
+		{Version: 7, Name: "space_snapshot_entries", Up: createSpaceSnapshotEntries},
+	}
+}
@@ -0,0 +1,274 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	collectionMigrations = "schema_migrations"
+	collectionLocks      = "schema_migrations_lock"
+	// lockID is the ID of the single lock document, which holds the owner and lease of the lock
+	lockID = "migrations"
+
+	// lockLease is how long a lock stays valid without being renewed, so that the
+	// lock of a crashed runner expires instead of blocking migrations for good
+	lockLease = time.Minute
+	// lockRetry is how often a waiting runner tries to take the lock
+	lockRetry = 2 * time.Second
+)
+
+// ErrLockLost is returned when the migration lock expired while migrations were running
+var ErrLockLost = errors.New("migration lock lost")
+
+// Record is an applied migration stored in the schema_migrations collection
+type Record struct {
+	Version    int       `json:"version" bson:"_id"`
+	Name       string    `json:"name" bson:"name"`
+	AppliedAt  time.Time `json:"applied_at" bson:"applied_at"`
+	DurationMs int64     `json:"duration_ms" bson:"duration_ms"`
+	AppliedBy  string    `json:"applied_by" bson:"applied_by"`
+}
+
+// Status describes a known migration and whether it was applied
+type Status struct {
+	Version    int        `json:"version"`
+	Name       string     `json:"name"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	DurationMs *int64     `json:"duration_ms,omitempty"`
+}
+
+// Runner applies migrations to the database and reports their state
+type Runner struct {
+	dbService  *db_service.DbService
+	migrations []Migration
+	owner      string
+	logger     zerolog.Logger
+}
+
+// NewRunner creates a runner for all migrations of the service
+func NewRunner(dbService *db_service.DbService, logger zerolog.Logger) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		dbService:  dbService,
+		migrations: All(),
+		owner:      fmt.Sprintf("%s/%s", host, uuid.New().String()),
+		logger:     logger.With().Str("component", "migrations").Logger(),
+	}
+}
+
+// Status lists all known migrations in version order with their applied state
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+			status.DurationMs = &record.DurationMs
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending lists the IDs of the migrations that have not been applied yet
+func (r *Runner) Pending(ctx context.Context) ([]string, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, migration := range r.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration.ID())
+		}
+	}
+	return pending, nil
+}
+
+// Up applies all pending migrations in version order and returns the applied ones.
+// It waits for the migration lock until ctx is done, so that replicas starting at
+// the same time run each migration once. Up stops at the first failing migration.
+func (r *Runner) Up(ctx context.Context) ([]Record, error) {
+	if err := r.acquireLock(ctx); err != nil {
+		return nil, err
+	}
+	defer r.releaseLock()
+
+	// Cancel the migrations when the lock can no longer be renewed
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go r.renewLock(ctx, cancel)
+
+	// Another runner may have applied migrations while this one waited for the lock
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Record
+	for _, migration := range r.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		logger := r.logger.With().Str("migration", migration.ID()).Logger()
+		logger.Info().Msg("Applying migration")
+		start := time.Now()
+		if err := migration.Up(ctx, r.dbService); err != nil {
+			if cause := context.Cause(ctx); errors.Is(cause, ErrLockLost) {
+				err = cause
+			}
+			return done, fmt.Errorf("migration %s failed: %w", migration.ID(), err)
+		}
+
+		record := Record{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			AppliedAt:  time.Now(),
+			DurationMs: time.Since(start).Milliseconds(),
+			AppliedBy:  r.owner,
+		}
+		if _, err := r.dbService.GetCollection(collectionMigrations).InsertOne(ctx, record); err != nil {
+			return done, fmt.Errorf("failed to record migration %s: %w", migration.ID(), err)
+		}
+		logger.Info().Int64("duration_ms", record.DurationMs).Msg("Migration applied")
+		done = append(done, record)
+	}
+	return done, nil
+}
+
+// AppliedAt returns when the migration with version was applied, or nil when it
+// has not been applied
+func AppliedAt(ctx context.Context, dbService *db_service.DbService, version int) (*time.Time, error) {
+	var record Record
+	err := dbService.GetCollection(collectionMigrations).FindOne(ctx, bson.M{"_id": version}).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migration: %w", err)
+	}
+	return &record.AppliedAt, nil
+}
+
+// applied returns the applied migrations by version
+func (r *Runner) applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := r.dbService.GetCollection(collectionMigrations).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// acquireLock takes the migration lock, waiting for another runner to finish until ctx is done
+func (r *Runner) acquireLock(ctx context.Context) error {
+	for {
+		taken, err := r.tryLock(ctx)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return nil
+		}
+
+		r.logger.Info().Msg("Waiting for another instance to finish migrations")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the migration lock: %w", ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// tryLock takes the lock if it is free or expired. A lock held by another runner
+// makes the upsert insert a second document with the same ID, which fails with a
+// duplicate key error.
+func (r *Runner) tryLock(ctx context.Context) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": lockID,
+		"$or": bson.A{
+			bson.M{"owner": r.owner},
+			bson.M{"expires_at": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": r.owner, "acquired_at": now, "expires_at": now.Add(lockLease)}}
+
+	_, err := r.dbService.GetCollection(collectionLocks).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	return true, nil
+}
+
+// renewLock extends the lease of the lock until ctx is done and cancels ctx with
+// ErrLockLost when the lock was taken over
+func (r *Runner) renewLock(ctx context.Context, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(lockLease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := r.dbService.GetCollection(collectionLocks).UpdateOne(ctx,
+			bson.M{"_id": lockID, "owner": r.owner},
+			bson.M{"$set": bson.M{"expires_at": time.Now().Add(lockLease)}},
+		)
+		if err != nil {
+			// A failed renewal is retried, the lease outlasts a few attempts
+			r.logger.Warn().Err(err).Msg("Failed to renew the migration lock")
+			continue
+		}
+		if result.MatchedCount == 0 {
+			cancel(ErrLockLost)
+			return
+		}
+	}
+}
+
+// releaseLock frees the lock for other runners
+func (r *Runner) releaseLock() {
+	ctx, cancel := r.dbService.CreateContext()
+	defer cancel()
+
+	if _, err := r.dbService.GetCollection(collectionLocks).DeleteOne(ctx, bson.M{"_id": lockID, "owner": r.owner}); err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to release the migration lock, it expires with its lease")
+	}
+}
@@ -0,0 +1,99 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// UseJSONFieldNames makes binding validation errors refer to fields by their JSON
+// names, so that field errors match the request body the client sent
+func UseJSONFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// InvalidBody reports a request body that could not be bound, with an error per invalid field
+func InvalidBody(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var sizeErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &sizeErr):
+		return TooLarge(sizeErr.Limit)
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, FieldError{Field: fieldPath(fieldErr), Message: validationMessage(fieldErr)})
+		}
+		return Validation("validation_failed", "The request body contains invalid fields", fields...)
+	case errors.As(err, &typeErr):
+		return Validation("validation_failed", "The request body contains invalid fields",
+			FieldError{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type)})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return Validation("malformed_body", "The request body is not valid JSON")
+	case errors.Is(err, io.EOF):
+		return Validation("missing_body", "The request body is required")
+	default:
+		return Validation("invalid_body", err.Error())
+	}
+}
+
+// InvalidQuery reports an invalid query parameter
+func InvalidQuery(parameter, message string) *Error {
+	return Validation("invalid_query", fmt.Sprintf("Invalid query parameter %s", parameter),
+		FieldError{Field: parameter, Message: message})
+}
+
+// fieldPath returns the path of the field below the request struct, e.g. head.email
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// validationMessage describes a failed validation rule
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "email":
+		return "must be an email address"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "min", "gte":
+		return "must be at least " + fieldErr.Param()
+	case "max", "lte":
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "lt":
+		return "must be less than " + fieldErr.Param()
+	case "len":
+		return "must have length " + fieldErr.Param()
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
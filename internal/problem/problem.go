@@ -0,0 +1,224 @@
+package problem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// ContentType is the media type of problem details responses
+const ContentType = "application/problem+json"
+
+// typePrefix prefixes the error code to form the problem type URI
+const typePrefix = "urn:problem:hospital-spaces:"
+
+// Kind classifies domain errors and determines their HTTP status
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnavailable
+	KindUnauthorized
+	KindForbidden
+	KindTooLarge
+	KindRateLimited
+)
+
+// status returns the HTTP status of the kind
+func (k Kind) status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError describes an invalid field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error with a stable code that clients can branch on. The
+// message is shown to clients, the wrapped cause is only logged.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	cause   error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Status returns the HTTP status the error is reported with
+func (e *Error) Status() int {
+	return e.Kind.status()
+}
+
+// Validation reports invalid input
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// NotFound reports a missing resource
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict reports a request that conflicts with the current state of a resource
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Unavailable reports a dependency that cannot serve the request right now
+func Unavailable(code, message string, cause error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Message: message, cause: cause}
+}
+
+// Unauthorized reports a missing or invalid credential
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden reports a caller that is not allowed to perform the request
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// TooLarge reports a request body above the size limit
+func TooLarge(limit int64) *Error {
+	return &Error{Kind: KindTooLarge, Code: "body_too_large", Message: fmt.Sprintf("The request body must not exceed %d bytes", limit)}
+}
+
+// RateLimited reports a client that exceeded its rate limit. The caller sets the Retry-After header.
+func RateLimited() *Error {
+	return &Error{Kind: KindRateLimited, Code: "rate_limited", Message: "Too many requests, retry later"}
+}
+
+// Internal wraps an unexpected error, e.g. from the database driver. Clients only
+// see the message; well-known causes such as duplicate keys and timeouts are
+// reported with their own status by Write.
+func Internal(cause error, message string) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: message, cause: cause}
+}
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Write reports err as an application/problem+json response and aborts the request.
+// The error is recorded on the gin context so that its cause ends up in the access log.
+func Write(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	e := From(err)
+	status := e.Status()
+	problem := Problem{
+		Type:      typePrefix + e.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      e.Code,
+		RequestID: logging.RequestID(c),
+		Errors:    e.Fields,
+	}
+
+	c.Abort()
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, problem)
+}
+
+// duplicateKeyIndex extracts the index name from a duplicate key error message
+var duplicateKeyIndex = regexp.MustCompile(`index: (\S+) dup key`)
+
+// indexField returns the field of a single field unique index from its name, e.g.
+// space_id_1 or space_id_live for the partial index on live records
+func indexField(name string) string {
+	for _, suffix := range []string{"_1", "_live"} {
+		if field, ok := strings.CutSuffix(name, suffix); ok {
+			return field
+		}
+	}
+	return name
+}
+
+// From maps any error to a domain error, e.g. to report the failed rows of a bulk
+// write the same way Write reports a failed request
+func From(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) && domainErr.Kind != KindInternal {
+		return domainErr
+	}
+
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		conflict := Conflict("duplicate_key", "A resource with the same unique value already exists")
+		if match := duplicateKeyIndex.FindStringSubmatch(err.Error()); match != nil {
+			conflict.Fields = []FieldError{{Field: indexField(match[1]), Message: "must be unique"}}
+		}
+		return conflict
+	case isUnavailable(err):
+		return Unavailable("service_unavailable", "The database is unavailable, retry later", err)
+	case domainErr != nil:
+		return domainErr
+	default:
+		return Internal(err, "Internal server error")
+	}
+}
+
+// isUnavailable reports errors caused by an unreachable database or an expired deadline
+func isUnavailable(err error) bool {
+	var selectionErr topology.ServerSelectionError
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) ||
+		mongo.IsTimeout(err) ||
+		mongo.IsNetworkError(err) ||
+		errors.As(err, &selectionErr)
+}
@@ -0,0 +1,22 @@
+package problem
+
+import (
+	"errors"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+)
+
+// Recovery turns panics into 500 problem responses and logs them with the request logger
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error().
+			Ctx(c.Request.Context()).
+			Interface("panic", recovered).
+			Str("path", c.Request.URL.Path).
+			Str("stack", string(debug.Stack())).
+			Msg("Recovered from panic")
+		Write(c, Internal(errors.New("panic"), "Internal server error"))
+	})
+}
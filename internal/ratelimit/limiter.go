@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is how long the client has to wait for the next token when the request was rejected
+	RetryAfter time.Duration
+}
+
+// Backend keeps token buckets and takes tokens from them
+type Backend interface {
+	// Take refills the bucket stored under key at rate tokens per second up to
+	// burst tokens and takes one token if available
+	Take(ctx context.Context, key string, rate float64, burst int) (Result, error)
+}
+
+// bucket is the state of a token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time elapsed since its last update and takes a token if available
+func (b *bucket) take(now time.Time, rate float64, burst int) Result {
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	b.updated = now
+	return takeToken(&b.tokens, rate)
+}
+
+// takeToken takes a token from tokens if one is available and describes the outcome
+func takeToken(tokens *float64, rate float64) Result {
+	if *tokens >= 1 {
+		*tokens--
+		return Result{Allowed: true, Remaining: int(*tokens)}
+	}
+	wait := (1 - *tokens) / rate
+	return Result{Allowed: false, RetryAfter: time.Duration(wait * float64(time.Second))}
+}
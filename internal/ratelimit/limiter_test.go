@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		tokens     float64
+		elapsed    time.Duration
+		rate       float64
+		burst      int
+		want       Result
+		wantTokens float64
+	}{
+		{"full bucket", 40, 0, 20, 40, Result{Allowed: true, Remaining: 39}, 39},
+		{"last token", 1, 0, 1, 2, Result{Allowed: true, Remaining: 0}, 0},
+		{"empty bucket", 0, 0, 1, 2, Result{RetryAfter: time.Second}, 0},
+		{"partial refill is not enough", 0, 500 * time.Millisecond, 1, 2, Result{RetryAfter: 500 * time.Millisecond}, 0.5},
+		{"refill to a whole token", 0.5, 500 * time.Millisecond, 1, 2, Result{Allowed: true, Remaining: 0}, 0},
+		{"remaining rounds down", 1, 1500 * time.Millisecond, 1, 5, Result{Allowed: true, Remaining: 1}, 1.5},
+		{"refill is capped at burst", 0, time.Hour, 5, 10, Result{Allowed: true, Remaining: 9}, 9},
+		{"slow rate", 0.25, 0, 0.1, 2, Result{RetryAfter: 7500 * time.Millisecond}, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bucket{tokens: tt.tokens, updated: start}
+			got := b.take(start.Add(tt.elapsed), tt.rate, tt.burst)
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if math.Abs(b.tokens-tt.wantTokens) > 1e-9 {
+				t.Errorf("expected %v tokens left, got %v", tt.wantTokens, b.tokens)
+			}
+			if !b.updated.Equal(start.Add(tt.elapsed)) {
+				t.Errorf("expected the bucket to be updated at %v, got %v", start.Add(tt.elapsed), b.updated)
+			}
+		})
+	}
+}
+
+func TestBucketBurstThenRefill(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &bucket{tokens: 3, updated: now}
+
+	for i := 2; i >= 0; i-- {
+		if result := b.take(now, 2, 3); !result.Allowed || result.Remaining != i {
+			t.Fatalf("expected the burst to allow the request with %d remaining, got %+v", i, result)
+		}
+	}
+	result := b.take(now, 2, 3)
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("expected a rejection retrying after 500ms, got %+v", result)
+	}
+	if result := b.take(now.Add(result.RetryAfter), 2, 3); !result.Allowed {
+		t.Errorf("expected a request after RetryAfter to be allowed, got %+v", result)
+	}
+}
+
+func TestTakeToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokens     float64
+		rate       float64
+		want       Result
+		wantTokens float64
+	}{
+		{"whole tokens", 3, 1, Result{Allowed: true, Remaining: 2}, 2},
+		{"exactly one token", 1, 1, Result{Allowed: true, Remaining: 0}, 0},
+		{"no tokens", 0, 4, Result{RetryAfter: 250 * time.Millisecond}, 0},
+		{"fraction of a token", 0.75, 1, Result{RetryAfter: 250 * time.Millisecond}, 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tt.tokens
+			if got := takeToken(&tokens, tt.rate); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if tokens != tt.wantTokens {
+				t.Errorf("expected %v tokens left, got %v", tt.wantTokens, tokens)
+			}
+		})
+	}
+}
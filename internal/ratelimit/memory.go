@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped from memory
+const sweepInterval = time.Minute
+
+// MemoryBackend keeps token buckets in process memory. Each replica limits on its
+// own, so the effective limit of a deployment grows with the number of replicas.
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryBackend creates an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{buckets: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+// Take implements Backend
+func (m *MemoryBackend) Take(_ context.Context, key string, rate float64, burst int) (Result, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		m.buckets[key] = b
+	}
+	return b.take(now, rate, burst), nil
+}
+
+// sweep drops buckets that were not used for a sweep interval. A dropped bucket is
+// recreated full, which is what it would have refilled to in most configurations.
+func (m *MemoryBackend) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) > sweepInterval {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rosadsky/ros-project-backend/internal/auth"
+	"github.com/rosadsky/ros-project-backend/internal/config"
+	"github.com/rosadsky/ros-project-backend/internal/logging"
+	"github.com/rosadsky/ros-project-backend/internal/problem"
+)
+
+// Response headers describing the rate limit applied to a request
+const (
+	HeaderLimit      = "X-RateLimit-Limit"
+	HeaderRemaining  = "X-RateLimit-Remaining"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Middleware limits the request rate and body size of each client using the rules
+// of the current configuration, so that reloaded limits apply to the next request.
+// It must run after the authenticator, whose principal identifies the client.
+// Requests are let through when the backend fails, a limiter outage must not take
+// the API down with it.
+func Middleware(reloader *config.Reloader, backend Backend) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := reloader.Current().Limits
+		if !limits.Enabled || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		rule := matchRule(limits, c.Request.Method, c.Request.URL.Path)
+
+		if rule.MaxBodyBytes > 0 {
+			if c.Request.ContentLength > rule.MaxBodyBytes {
+				problem.Write(c, problem.TooLarge(rule.MaxBodyBytes))
+				return
+			}
+			// Bodies without a Content-Length fail while being read, see problem.InvalidBody
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, rule.MaxBodyBytes)
+		}
+
+		key := rule.Name + "|" + clientKey(c)
+		result, err := backend.Take(c.Request.Context(), key, rule.RequestsPerSecond, rule.Burst)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Warn().Err(err).Str("rule", rule.Name).Msg("Rate limit check failed, letting the request through")
+			c.Next()
+			return
+		}
+
+		c.Header(HeaderLimit, strconv.Itoa(rule.Burst))
+		c.Header(HeaderRemaining, strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			c.Header(HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			problem.Write(c, problem.RateLimited())
+			return
+		}
+		c.Next()
+	}
+}
+
+// matchRule returns the first route rule matching the request, or the default rule
+func matchRule(limits config.LimitsConfig, method, path string) config.LimitRule {
+	for _, rule := range limits.Routes {
+		if !strings.HasPrefix(path, rule.PathPrefix) {
+			continue
+		}
+		if len(rule.Methods) > 0 && !slices.ContainsFunc(rule.Methods, func(m string) bool { return strings.EqualFold(m, method) }) {
+			continue
+		}
+		return rule
+	}
+	return limits.Default
+}
+
+// clientKey identifies the client of a request by its API key, or by its IP for anonymous callers
+func clientKey(c *gin.Context) string {
+	if principal := auth.FromContext(c); principal.KeyID != "" {
+		return "key:" + principal.KeyID
+	}
+	return "ip:" + c.ClientIP()
+}
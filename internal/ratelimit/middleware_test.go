@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/rosadsky/ros-project-backend/internal/config"
+)
+
+func TestMatchRule(t *testing.T) {
+	limits := config.LimitsConfig{
+		Default: config.LimitRule{Name: "default"},
+		Routes: []config.LimitRule{
+			{Name: "imports", PathPrefix: "/api/spaces:import", Methods: []string{"POST"}},
+			{Name: "writes", PathPrefix: "/api/spaces", Methods: []string{"POST", "PUT", "DELETE"}},
+			{Name: "reads", PathPrefix: "/api/spaces"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{"first matching rule", "POST", "/api/spaces:import", "imports"},
+		{"later rule when the method does not match", "PUT", "/api/spaces:import", "writes"},
+		{"methods are case insensitive", "post", "/api/spaces", "writes"},
+		{"rule without methods matches any method", "GET", "/api/spaces/123", "reads"},
+		{"default when no prefix matches", "POST", "/api/ambulances", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRule(limits, tt.method, tt.path); got.Name != tt.want {
+				t.Errorf("expected rule %q, got %q", tt.want, got.Name)
+			}
+		})
+	}
+}
+
+// TestMatchRuleShippedConfig checks that the imports of config/config.yaml are not
+// shadowed by the write rules, which would limit their bodies to 64 KiB
+func TestMatchRuleShippedConfig(t *testing.T) {
+	t.Setenv("AMBULANCE_API_CONFIG", "../../config/config.yaml")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		method       string
+		path         string
+		want         string
+		maxBodyBytes int64
+	}{
+		{"POST", "/api/spaces:import", "space-imports", 16 << 20},
+		{"POST", "/api/ambulances:import", "ambulance-imports", 16 << 20},
+		{"POST", "/api/spaces", "space-writes", 64 << 10},
+		{"PUT", "/api/ambulances/123", "ambulance-writes", 64 << 10},
+		{"GET", "/api/spaces", "default", 1 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rule := matchRule(cfg.Limits, tt.method, tt.path)
+			if rule.Name != tt.want || rule.MaxBodyBytes != tt.maxBodyBytes {
+				t.Errorf("expected rule %q with %d bytes, got %q with %d", tt.want, tt.maxBodyBytes, rule.Name, rule.MaxBodyBytes)
+			}
+		})
+	}
+}
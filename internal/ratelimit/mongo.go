@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rosadsky/ros-project-backend/internal/db_service"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collectionRateLimits stores the token buckets of the MongoDB backend
+const collectionRateLimits = "rate_limits"
+
+// MongoBackend keeps token buckets in MongoDB so that all replicas share the same
+// limits. Each Take is a single atomic update that refills and takes a token
+// using the database clock, so replica clocks do not need to agree.
+type MongoBackend struct {
+	dbService *db_service.DbService
+}
+
+// NewMongoBackend creates a backend storing buckets in the rate_limits collection
+func NewMongoBackend(dbService *db_service.DbService) *MongoBackend {
+	return &MongoBackend{dbService: dbService}
+}
+
+// mongoBucket is the stored state of a token bucket
+type mongoBucket struct {
+	Tokens float64 `bson:"tokens"`
+}
+
+// Take implements Backend
+func (m *MongoBackend) Take(ctx context.Context, key string, rate float64, burst int) (Result, error) {
+	ctx, cancel := m.dbService.WriteContext(ctx)
+	defer cancel()
+
+	// Buckets expire once they would have refilled completely, which keeps the collection small
+	ttl := time.Duration(float64(burst) / rate * float64(time.Second))
+	elapsedSeconds := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$$NOW", bson.M{"$ifNull": bson.A{"$updated_at", "$$NOW"}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		burst,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", burst}},
+			bson.M{"$multiply": bson.A{elapsedSeconds, rate}},
+		}},
+	}}
+	pipeline := bson.A{
+		bson.M{"$set": bson.M{"tokens": refilled, "updated_at": "$$NOW"}},
+		bson.M{"$set": bson.M{
+			"allowed":    bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens":     bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$tokens", 1}}, bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl.Milliseconds()}},
+		}},
+	}
+
+	var stored struct {
+		mongoBucket `bson:",inline"`
+		Allowed     bool `bson:"allowed"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := m.dbService.GetCollection(collectionRateLimits).
+		FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).
+		Decode(&stored)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	if stored.Allowed {
+		return Result{Allowed: true, Remaining: int(math.Floor(stored.Tokens))}, nil
+	}
+	tokens := stored.Tokens
+	return takeToken(&tokens, rate), nil
+}
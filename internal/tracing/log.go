@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds the trace and span IDs of the event context to log lines, so that
+// log lines written with Ctx(c.Request.Context()) can be found from a trace
+type LogHook struct{}
+
+// Run implements zerolog.Hook
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	ctx := e.GetCtx()
+	if ctx == nil {
+		return
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+	e.Str("trace_id", spanContext.TraceID().String()).Str("span_id", spanContext.SpanID().String())
+}